@@ -0,0 +1,172 @@
+package sawyer
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// A TokenSource supplies OAuth2 access tokens, like the TokenSource of
+// golang.org/x/oauth2, without depending on it.  Token is called before each
+// request, so the source should cache the token and refresh it when it
+// expires.  If the source also has an Invalidate method, it is called when a
+// request is rejected with 401 Unauthorized, before the request is sent once
+// more with a new token.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// SetTokenSource sends requests built by this Client with a bearer token from
+// the given source, instead of one set with SetBearerToken.
+func (c *Client) SetTokenSource(ts TokenSource) {
+	c.tokens = ts
+}
+
+// SetReauth sets a function that authenticates the Client again, such as by
+// logging in for a new session, when a request is rejected with 401
+// Unauthorized.  If it succeeds, the request is sent once more with the
+// Client's new credentials.  A second 401 response is returned as is.  It is
+// not called by several goroutines at once: requests rejected while it runs
+// wait for it, and are sent again with the credentials it set.
+//
+//	client.SetReauth(func(c *sawyer.Client) error {
+//		token, err := login()
+//		c.SetBearerToken(token)
+//		return err
+//	})
+func (c *Client) SetReauth(reauth func(*Client) error) {
+	c.reauth = reauth
+}
+
+// sendAuthorized sends the request with a token from the Client's
+// TokenSource, if it has one.  A 401 Unauthorized response is retried once,
+// with a new token or after the Client's reauth function runs, if the body
+// can be sent again.
+func (r *Request) sendAuthorized(httpreq *http.Request) (*http.Response, error) {
+	httpreq, err := r.withIdempotencyKey(httpreq)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.authorize(httpreq); err != nil {
+		return nil, err
+	}
+
+	httpres, err := r.roundTrip(httpreq)
+	if err != nil || httpres.StatusCode != http.StatusUnauthorized || !r.canReauthorize() || !r.rewindable(httpreq) {
+		return httpres, err
+	}
+
+	io.Copy(ioutil.Discard, httpres.Body)
+	httpres.Body.Close()
+
+	if err := r.reauthorize(httpreq); err != nil {
+		return nil, err
+	}
+
+	if err := r.rewindBody(httpreq); err != nil {
+		return nil, err
+	}
+	return r.roundTrip(httpreq)
+}
+
+func (r *Request) authorize(httpreq *http.Request) error {
+	if r.client == nil || r.client.tokens == nil {
+		return nil
+	}
+
+	token, err := r.client.tokens.Token()
+	if err != nil {
+		return err
+	}
+	httpreq.Header.Set(authHeader, "Bearer "+token)
+	return nil
+}
+
+// reauthorize gets new credentials for the request after a 401 Unauthorized
+// response.  Requests rejected at once share one refresh: a request that was
+// sent before the last refresh finished only picks up the new credentials.
+func (r *Request) reauthorize(httpreq *http.Request) error {
+	c := r.client
+	if c.auth != nil {
+		c.auth.refresh.Lock()
+		defer c.auth.refresh.Unlock()
+	}
+
+	if _, _, _, generation := c.credentials(); generation == r.generation {
+		if invalidator, ok := c.tokens.(interface{ Invalidate() }); ok {
+			invalidator.Invalidate()
+		}
+
+		if c.reauth != nil {
+			if err := c.reauth(c); err != nil {
+				return err
+			}
+		}
+		c.refreshed()
+	}
+
+	username, password, token, generation := c.credentials()
+	r.generation = generation
+	if c.reauth != nil {
+		if len(token) > 0 {
+			httpreq.Header.Set(authHeader, "Bearer "+token)
+		} else if len(username) > 0 || len(password) > 0 {
+			httpreq.SetBasicAuth(username, password)
+		}
+	}
+	return r.authorize(httpreq)
+}
+
+func (r *Request) canReauthorize() bool {
+	return r.client != nil && (r.client.tokens != nil || r.client.reauth != nil)
+}
+
+// authState guards a Client's credentials.  The generation counts the
+// refreshes after 401 responses, so requests know if theirs is stale.
+type authState struct {
+	sync.RWMutex
+	refresh    sync.Mutex
+	generation uint64
+}
+
+// lockAuth locks the Client's credentials for writing or reading, and returns
+// the function that unlocks them.  Clients not built with New are not locked.
+func (c *Client) lockAuth(write bool) func() {
+	switch {
+	case c.auth == nil:
+		return func() {}
+	case write:
+		c.auth.Lock()
+		return c.auth.Unlock
+	default:
+		c.auth.RLock()
+		return c.auth.RUnlock
+	}
+}
+
+func (c *Client) credentials() (username, password, token string, generation uint64) {
+	unlock := c.lockAuth(false)
+	defer unlock()
+	if c.auth != nil {
+		generation = c.auth.generation
+	}
+	return c.username, c.password, c.token, generation
+}
+
+func (c *Client) refreshed() {
+	unlock := c.lockAuth(true)
+	defer unlock()
+	if c.auth != nil {
+		c.auth.generation += 1
+	}
+}
+
+// setCredentials sets the Client's credentials on a new Request.
+func (r *Request) setCredentials() {
+	username, password, token, generation := r.client.credentials()
+	r.generation = generation
+	r.SetBasicAuth(username, password)
+	r.SetBearerToken(token)
+}
@@ -0,0 +1,188 @@
+package sawyer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTokenSource(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"id":1,"login":"sawyer"}`+"\n", string(body))
+
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	tokens := &testTokenSource{}
+	setup.Client.SetBearerToken("static")
+	setup.Client.SetTokenSource(tokens)
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, tokens.invalidated)
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, 3, requests)
+}
+
+func TestTokenSourceRetriesOnce(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	tokens := &testTokenSource{}
+	setup.Client.SetTokenSource(tokens)
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 401, req.Get().StatusCode)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, tokens.invalidated)
+
+	tokens.err = errors.New("expired refresh token")
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "expired refresh token", req.Get().Error())
+	assert.Equal(t, 2, requests)
+}
+
+type testTokenSource struct {
+	current     int
+	invalidated int
+	err         error
+}
+
+func (s *testTokenSource) Token() (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	if s.current == 0 {
+		s.current += 1
+	}
+	return fmt.Sprintf("token-%d", s.current), nil
+}
+
+func (s *testTokenSource) Invalidate() {
+	s.invalidated += 1
+	s.current += 1
+}
+
+func TestReauth(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"id":1,"login":"sawyer"}`+"\n", string(body))
+
+		if r.Header.Get("Authorization") != "Bearer session-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	logins := 0
+	setup.Client.SetBearerToken("session-1")
+	setup.Client.SetReauth(func(c *Client) error {
+		logins += 1
+		c.SetBearerToken(fmt.Sprintf("session-%d", logins+1))
+		return nil
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, logins)
+
+	setup.Client.SetBearerToken("session-0")
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+	assert.Equal(t, 401, req.Post().StatusCode)
+	assert.Equal(t, 4, requests)
+	assert.Equal(t, 2, logins)
+}
+
+func TestReauthError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	setup.Client.SetReauth(func(c *Client) error {
+		return errors.New("bad password")
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "bad password", req.Get().Error())
+	assert.Equal(t, 1, requests)
+}
+
+func TestReauthConcurrently(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer session-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var logins int32
+	setup.Client.SetBearerToken("session-1")
+	setup.Client.SetReauth(func(c *Client) error {
+		atomic.AddInt32(&logins, 1)
+		c.SetBearerToken("session-2")
+		return nil
+	})
+
+	reqs := make([]*Request, 8)
+	for i := range reqs {
+		req, err := setup.Client.NewRequest("user")
+		assert.Equal(t, nil, err)
+		reqs[i] = req
+	}
+
+	for _, res := range setup.Client.DoAll(reqs, nil, len(reqs)) {
+		assert.Equal(t, 200, res.StatusCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&logins))
+}
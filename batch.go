@@ -0,0 +1,49 @@
+package sawyer
+
+import (
+	"sync"
+)
+
+// DoAll sends the requests with up to concurrency at a time, and returns
+// their responses in the same order.  Each request is sent with its Method,
+// which is GET for new requests, and a successful response is decoded into
+// the output at the same index, unless it is nil.  Errors are held by each
+// Response.  A concurrency below 1 sends one request at a time.
+//
+//	reqs := []*sawyer.Request{userReq, reposReq}
+//	outputs := []interface{}{&user, &repos}
+//	for _, res := range client.DoAll(reqs, outputs, 4) {
+//		if res.AnyError() {
+//			...
+//		}
+//	}
+func (c *Client) DoAll(reqs []*Request, outputs []interface{}, concurrency int) []*Response {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	responses := make([]*Response, len(reqs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency && i < len(reqs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				res := reqs[i].Do(reqs[i].Method)
+				if i < len(outputs) && outputs[i] != nil {
+					res.decode(outputs[i])
+				}
+				responses[i] = res
+			}
+		}()
+	}
+
+	for i := range reqs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return responses
+}
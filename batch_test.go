@@ -0,0 +1,96 @@
+package sawyer
+
+import (
+	"context"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDoAll(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	var mutex sync.Mutex
+	active, most := 0, 0
+
+	setup.Mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		active += 1
+		if active > most {
+			most = active
+		}
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		active -= 1
+		mutex.Unlock()
+
+		login := strings.TrimPrefix(r.URL.Path, "/users/")
+		if login == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"login": %q}`, login)
+	})
+
+	logins := []string{"a", "b", "missing", "c", "d"}
+	reqs := make([]*Request, len(logins))
+	outputs := make([]interface{}, len(logins))
+	for i, login := range logins {
+		req, err := setup.Client.NewRequest("users/" + login)
+		assert.Equal(t, nil, err)
+		reqs[i] = req
+		outputs[i] = &TestUser{}
+	}
+	outputs[3] = nil
+
+	responses := setup.Client.DoAll(reqs, outputs, 2)
+	assert.Equal(t, len(logins), len(responses))
+	assert.Equal(t, 2, most)
+
+	for i, res := range responses {
+		if logins[i] == "missing" {
+			assert.Equal(t, 404, res.StatusCode)
+			assert.Equal(t, true, res.IsApiError())
+			continue
+		}
+
+		assert.Equal(t, 200, res.StatusCode)
+		if outputs[i] == nil {
+			assert.Equal(t, false, res.BodyClosed)
+			res.Consume()
+			continue
+		}
+		assert.Equal(t, logins[i], outputs[i].(*TestUser).Login)
+	}
+}
+
+func TestDoAllContext(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled, err := setup.Client.NewRequestWithContext(ctx, "user")
+	assert.Equal(t, nil, err)
+
+	responses := setup.Client.DoAll([]*Request{req, cancelled}, nil, 0)
+	assert.Equal(t, 204, responses[0].StatusCode)
+	assert.Equal(t, true, responses[1].IsError())
+}
@@ -0,0 +1,22 @@
+// Package brotli registers a decompressor for responses with a
+// "Content-Encoding: br" header.  It is kept separate so that only clients
+// that need it import the brotli library:
+//
+//	import _ "github.com/lostisland/go-sawyer/brotli"
+package brotli
+
+import (
+	"github.com/andybalholm/brotli"
+	"github.com/lostisland/go-sawyer"
+	"io"
+	"io/ioutil"
+)
+
+// Encoding is the Content-Encoding that the decompressor is registered for.
+const Encoding = "br"
+
+func init() {
+	sawyer.AddContentDecoder(Encoding, func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	})
+}
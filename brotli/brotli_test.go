@@ -0,0 +1,43 @@
+package brotli
+
+import (
+	"github.com/andybalholm/brotli"
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBrotliResponse(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "br")
+		bw := brotli.NewWriter(w)
+		bw.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+		bw.Close()
+	})
+
+	client, err := sawyer.NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, "", res.Header.Get("Content-Encoding"))
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+type TestUser struct {
+	Id    int    `json:"id"`
+	Login string `json:"login"`
+}
@@ -0,0 +1,235 @@
+package sawyer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Cacher stores responses to GET requests, so that they can be replayed
+// while fresh, or revalidated with an ETag or Last-Modified date instead of
+// being downloaded again.  Responses with a Vary header should only be
+// returned for requests with the same values for the headers it lists.
+type Cacher interface {
+	Get(req *http.Request) *CachedResponse
+	Set(req *http.Request, res *CachedResponse)
+}
+
+// A CachedResponse is a response saved by a Cacher.  Its body is stored
+// already decompressed.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// StoredAt is when the response was received.  It is used with the
+	// Cache-Control and Expires headers to tell if the response is fresh.
+	StoredAt time.Time
+}
+
+// Response rebuilds an *http.Response for the given request from the cached
+// response.
+func (c *CachedResponse) Response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.StatusCode, http.StatusText(c.StatusCode)),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// Fresh reports whether the response can still be used at the given time
+// without revalidating it.
+func (c *CachedResponse) Fresh(now time.Time) bool {
+	if c == nil || c.StoredAt.IsZero() {
+		return false
+	}
+
+	lifetime, ok := freshnessLifetime(c.Header, c.StoredAt)
+	if !ok {
+		return false
+	}
+
+	age := now.Sub(c.StoredAt)
+	if secs, err := strconv.Atoi(c.Header.Get(ageHeader)); err == nil {
+		age += time.Duration(secs) * time.Second
+	}
+	return age < lifetime
+}
+
+// cacheable reports whether the request goes through the Client's Cacher.
+// Streamed requests do not, since storing the response would read all of it.
+func (r *Request) cacheable() bool {
+	return r.client != nil && r.client.Cacher != nil && r.Method == GetMethod &&
+		len(r.Header.Get(rangeHeader)) == 0 && !r.stream
+}
+
+// revalidate returns a copy of the request with conditional headers for the
+// cached response.
+func revalidate(req *http.Request, cached *CachedResponse) *http.Request {
+	if cached == nil {
+		return req
+	}
+
+	etag := cached.Header.Get(etagHeader)
+	modified := cached.Header.Get(lastModifiedHeader)
+	if len(etag) == 0 && len(modified) == 0 {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if len(etag) > 0 {
+		req.Header.Set(ifNoneMatchHeader, etag)
+	}
+	if len(modified) > 0 {
+		req.Header.Set(ifModifiedSinceHeader, modified)
+	}
+	return req
+}
+
+// SetIfModifiedSince sets the If-Modified-Since header, so that the server can
+// respond with 304 Not Modified if the resource has not changed since then.
+func (r *Request) SetIfModifiedSince(t time.Time) {
+	r.Header.Set(ifModifiedSinceHeader, t.UTC().Format(http.TimeFormat))
+}
+
+// cache replays the cached response if the server responded with 304 Not
+// Modified, unless RevalidateTransparently is unset.  Otherwise, successful
+// responses with an ETag, a Last-Modified date, or a freshness lifetime are
+// stored.
+func (c *Client) cache(req *http.Request, res *http.Response, cached *CachedResponse) (*http.Response, error) {
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		c.refresh(req, res, cached)
+		if !c.RevalidateTransparently {
+			return res, nil
+		}
+
+		res.Body.Close()
+		return cached.Response(req), nil
+	}
+
+	if !c.storable(res) {
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	c.Cacher.Set(req, &CachedResponse{res.StatusCode, res.Header.Clone(), body, c.now()})
+	return res, nil
+}
+
+// refresh updates a cached response with the headers of a 304 Not Modified
+// response.
+func (c *Client) refresh(req *http.Request, res *http.Response, cached *CachedResponse) {
+	for _, key := range []string{cacheControlHeader, expiresHeader, dateHeader, ageHeader, etagHeader, lastModifiedHeader} {
+		if value, ok := res.Header[key]; ok {
+			cached.Header[key] = value
+		}
+	}
+	cached.StoredAt = c.now()
+	c.Cacher.Set(req, cached)
+}
+
+func (c *Client) storable(res *http.Response) bool {
+	if res.StatusCode != http.StatusOK {
+		return false
+	}
+
+	directives := cacheControl(res.Header)
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	if _, ok := directives["private"]; ok && !c.CachePrivateResponses {
+		return false
+	}
+
+	// a response that varies on everything can never be reused
+	for _, vary := range res.Header.Values(varyHeader) {
+		if strings.Contains(vary, "*") {
+			return false
+		}
+	}
+
+	if len(res.Header.Get(etagHeader)) > 0 || len(res.Header.Get(lastModifiedHeader)) > 0 {
+		return true
+	}
+	lifetime, ok := freshnessLifetime(res.Header, c.now())
+	return ok && lifetime > 0
+}
+
+func (c *Client) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
+// freshnessLifetime returns how long a response is fresh for, from the
+// max-age directive, or else the Expires header.  Expires is measured from the
+// Date header, or from when the response was received if it has none.
+func freshnessLifetime(header http.Header, received time.Time) (time.Duration, bool) {
+	directives := cacheControl(header)
+	if _, ok := directives["no-cache"]; ok {
+		return 0, false
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		secs, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	expires, err := http.ParseTime(header.Get(expiresHeader))
+	if err != nil {
+		return 0, false
+	}
+
+	date, err := http.ParseTime(header.Get(dateHeader))
+	if err != nil {
+		date = received
+	}
+	return expires.Sub(date), true
+}
+
+// cacheControl parses the Cache-Control header into a map of lowercased
+// directives to their values.
+func cacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range header.Values(cacheControlHeader) {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if len(name) > 0 {
+				directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+	return directives
+}
+
+const (
+	ageHeader             = "Age"
+	cacheControlHeader    = "Cache-Control"
+	dateHeader            = "Date"
+	etagHeader            = "ETag"
+	expiresHeader         = "Expires"
+	ifModifiedSinceHeader = "If-Modified-Since"
+	ifNoneMatchHeader     = "If-None-Match"
+	lastModifiedHeader    = "Last-Modified"
+	varyHeader            = "Vary"
+)
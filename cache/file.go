@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/lostisland/go-sawyer"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileCache keeps cached responses on disk, one file per URL and set of Vary
+// header values, so that they survive restarts.  A missing or corrupt file is
+// treated as a cache miss.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a FileCache that stores responses in dir.  The
+// directory is created when the first response is stored.
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir}
+}
+
+// Get returns the cached response for the request, or nil.
+func (c *FileCache) Get(req *http.Request) *sawyer.CachedResponse {
+	data, err := ioutil.ReadFile(c.path(varyKey(req, c.vary(req))))
+	if err != nil {
+		return nil
+	}
+
+	res := &sawyer.CachedResponse{}
+	if err := json.Unmarshal(data, res); err != nil || res.StatusCode == 0 {
+		return nil
+	}
+	return res
+}
+
+// Set stores the response for the request.  Errors writing to disk are
+// ignored, leaving the response uncached.
+func (c *FileCache) Set(req *http.Request, res *sawyer.CachedResponse) {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+
+	vary := varyHeaders(res.Header)
+	if c.writeFile(key(req)+".vary", []byte(strings.Join(vary, "\n"))) != nil {
+		return
+	}
+	c.writeFile(varyKey(req, vary), data)
+}
+
+// vary returns the Vary header names stored for the request's URL.
+func (c *FileCache) vary(req *http.Request) []string {
+	data, err := ioutil.ReadFile(c.path(key(req) + ".vary"))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// writeFile writes to a temporary file first, so that concurrent readers
+// never see a partial file.
+func (c *FileCache) writeFile(name string, data []byte) error {
+	tmp, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(name))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (c *FileCache) path(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// varyKey builds a cache key from the request's URL and the values of the
+// given request headers.
+func varyKey(req *http.Request, vary []string) string {
+	k := key(req)
+	for _, name := range vary {
+		k += "\n" + name + ": " + strings.Join(req.Header.Values(name), ", ")
+	}
+	return k
+}
+
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); len(name) > 0 {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestFileCacheStoresResponse(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sawyer")
+	res := response("a")
+	res.Header.Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+	NewFileCache(dir).Set(get(t, "/a"), res)
+
+	cached := NewFileCache(dir).Get(get(t, "/a"))
+	assert.Equal(t, 200, cached.StatusCode)
+	assert.Equal(t, `"a"`, cached.Header.Get("ETag"))
+	assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", cached.Header.Get("Last-Modified"))
+	assert.Equal(t, "a", string(cached.Body))
+}
+
+func TestFileCacheMiss(t *testing.T) {
+	cache := NewFileCache(filepath.Join(t.TempDir(), "missing"))
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(get(t, "/a")))
+}
+
+func TestFileCacheCorruptFile(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+	cache.Set(get(t, "/a"), response("a"))
+
+	err := ioutil.WriteFile(cache.path(varyKey(get(t, "/a"), nil)), []byte("{nope"), 0600)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(get(t, "/a")))
+}
+
+func TestFileCacheVary(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+
+	req := get(t, "/a")
+	req.Header.Set("Accept", "application/json")
+	res := response("json")
+	res.Header.Set("Vary", "accept")
+	cache.Set(req, res)
+
+	assert.Equal(t, "json", string(cache.Get(req).Body))
+
+	other := get(t, "/a")
+	other.Header.Set("Accept", "application/xml")
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(other))
+}
+
+func TestFileCacheConcurrentAccess(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := fmt.Sprintf("/%d", i)
+			cache.Set(get(t, path), response(path))
+			cache.Get(get(t, path))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		path := fmt.Sprintf("/%d", i)
+		assert.Equal(t, path, string(cache.Get(get(t, path)).Body))
+	}
+
+	files, err := os.ReadDir(cache.dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 20, len(files))
+}
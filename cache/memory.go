@@ -0,0 +1,110 @@
+// Package cache provides Cacher implementations for a sawyer.Client.
+//
+//	client.Cacher = cache.NewMemoryCache(100)
+package cache
+
+import (
+	"container/list"
+	"github.com/lostisland/go-sawyer"
+	"net/http"
+	"sync"
+)
+
+// MemoryCache keeps cached responses in memory, one per URL and set of Vary
+// request header values.  Once it holds maxEntries responses, the least
+// recently used response is evicted.
+type MemoryCache struct {
+	maxEntries int
+	entries    map[string]*list.Element
+	vary       map[string][]string
+	urls       map[string]int
+	lru        *list.List
+	mutex      sync.Mutex
+}
+
+type memoryEntry struct {
+	key string
+	url string
+	res *sawyer.CachedResponse
+}
+
+// NewMemoryCache returns a MemoryCache that holds up to maxEntries responses.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		vary:       make(map[string][]string),
+		urls:       make(map[string]int),
+		lru:        list.New(),
+	}
+}
+
+// Get returns a copy of the cached response for the request's URL, or nil.  If
+// the response had a Vary header, the request must have the same values for
+// the headers it lists.
+func (c *MemoryCache) Get(req *http.Request) *sawyer.CachedResponse {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	el, ok := c.entries[varyKey(req, c.vary[key(req)])]
+	if !ok {
+		return nil
+	}
+
+	c.lru.MoveToFront(el)
+	return copyResponse(el.Value.(*memoryEntry).res)
+}
+
+// Set stores a copy of the response for the request's URL.
+func (c *MemoryCache) Set(req *http.Request, res *sawyer.CachedResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	u := key(req)
+	vary := varyHeaders(res.Header)
+	c.vary[u] = vary
+
+	k := varyKey(req, vary)
+	if el, ok := c.entries[k]; ok {
+		el.Value.(*memoryEntry).res = copyResponse(res)
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[k] = c.lru.PushFront(&memoryEntry{k, u, copyResponse(res)})
+	c.urls[u] += 1
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back().Value.(*memoryEntry)
+		c.lru.Remove(c.lru.Back())
+		delete(c.entries, oldest.key)
+
+		// the Vary headers are kept while other responses for the URL are
+		c.urls[oldest.url] -= 1
+		if c.urls[oldest.url] == 0 {
+			delete(c.urls, oldest.url)
+			delete(c.vary, oldest.url)
+		}
+	}
+}
+
+// Len returns the number of cached responses.
+func (c *MemoryCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+func key(req *http.Request) string {
+	return req.URL.String()
+}
+
+func copyResponse(res *sawyer.CachedResponse) *sawyer.CachedResponse {
+	body := make([]byte, len(res.Body))
+	copy(body, res.Body)
+	return &sawyer.CachedResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       body,
+		StoredAt:   res.StoredAt,
+	}
+}
@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMemoryCacheEvictsOldest(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set(get(t, "/a"), response("a"))
+	cache.Set(get(t, "/b"), response("b"))
+	cache.Set(get(t, "/c"), response("c"))
+
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(get(t, "/a")))
+	assert.Equal(t, "b", string(cache.Get(get(t, "/b")).Body))
+	assert.Equal(t, "c", string(cache.Get(get(t, "/c")).Body))
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set(get(t, "/a"), response("a"))
+	cache.Set(get(t, "/b"), response("b"))
+	cache.Get(get(t, "/a"))
+	cache.Set(get(t, "/c"), response("c"))
+
+	assert.Equal(t, "a", string(cache.Get(get(t, "/a")).Body))
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(get(t, "/b")))
+}
+
+func TestMemoryCacheStoresResponse(t *testing.T) {
+	cache := NewMemoryCache(2)
+	res := response("a")
+	res.Header.Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+	cache.Set(get(t, "/a"), res)
+	res.Body[0] = 'b'
+	res.Header.Set("ETag", "changed")
+
+	cached := cache.Get(get(t, "/a"))
+	assert.Equal(t, 200, cached.StatusCode)
+	assert.Equal(t, `"a"`, cached.Header.Get("ETag"))
+	assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", cached.Header.Get("Last-Modified"))
+	assert.Equal(t, "a", string(cached.Body))
+
+	cache.Set(get(t, "/a"), response("c"))
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, "c", string(cache.Get(get(t, "/a")).Body))
+}
+
+func TestMemoryCacheVary(t *testing.T) {
+	cache := NewMemoryCache(10)
+
+	json := get(t, "/a")
+	json.Header.Set("Accept", "application/json")
+	res := response("json")
+	res.Header.Set("Vary", "accept")
+	cache.Set(json, res)
+
+	xml := get(t, "/a")
+	xml.Header.Set("Accept", "application/xml")
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(xml))
+
+	res = response("xml")
+	res.Header.Set("Vary", "Accept")
+	cache.Set(xml, res)
+
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, "json", string(cache.Get(json).Body))
+	assert.Equal(t, "xml", string(cache.Get(xml).Body))
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(get(t, "/a")))
+}
+
+func TestMemoryCacheEvictsVaryEntries(t *testing.T) {
+	cache := NewMemoryCache(2)
+
+	json := get(t, "/a")
+	json.Header.Set("Accept", "application/json")
+	res := response("json")
+	res.Header.Set("Vary", "Accept")
+	cache.Set(json, res)
+
+	xml := get(t, "/a")
+	xml.Header.Set("Accept", "application/xml")
+	res = response("xml")
+	res.Header.Set("Vary", "Accept")
+	cache.Set(xml, res)
+
+	cache.Set(get(t, "/b"), response("b"))
+
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(json))
+	assert.Equal(t, "xml", string(cache.Get(xml).Body))
+	assert.Equal(t, "b", string(cache.Get(get(t, "/b")).Body))
+
+	cache.Set(get(t, "/c"), response("c"))
+	cache.Set(get(t, "/d"), response("d"))
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, (*sawyer.CachedResponse)(nil), cache.Get(xml))
+	_, ok := cache.vary[key(xml)]
+	assert.Equal(t, false, ok)
+	assert.Equal(t, 2, len(cache.vary))
+	assert.Equal(t, 2, len(cache.urls))
+}
+
+func TestMemoryCacheVaryWithClient(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	requests := 0
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		head := w.Header()
+		head.Set("Cache-Control", "max-age=60")
+		head.Set("Vary", "Accept")
+		if r.Header.Get("Accept") == "application/xml" {
+			head.Set("Content-Type", "application/xml")
+			w.Write([]byte(`<user><login>sawyer-xml</login></user>`))
+			return
+		}
+		head.Set("Content-Type", "application/json")
+		w.Write([]byte(`{"login": "sawyer-json"}`))
+	})
+
+	client, err := sawyer.NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+	client.Cacher = NewMemoryCache(10)
+
+	for i := 0; i < 2; i++ {
+		for _, accept := range []string{"application/json", "application/xml"} {
+			req, err := client.NewRequest("user")
+			assert.Equal(t, nil, err)
+			req.Header.Set("Accept", accept)
+
+			user := &TestUser{}
+			res := req.Get()
+			assert.Equal(t, nil, res.Decode(user))
+			assert.Equal(t, accept, res.Header.Get("Content-Type"))
+		}
+	}
+	assert.Equal(t, 2, requests)
+}
+
+func TestMemoryCacheWithClient(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"login": "sawyer"}`))
+	})
+
+	client, err := sawyer.NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+	client.Cacher = NewMemoryCache(10)
+
+	for i := 0; i < 2; i++ {
+		req, err := client.NewRequest("user")
+		assert.Equal(t, nil, err)
+
+		user := &TestUser{}
+		res := req.Get()
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, nil, res.Decode(user))
+		assert.Equal(t, "sawyer", user.Login)
+	}
+}
+
+func get(t *testing.T, path string) *http.Request {
+	req, err := http.NewRequest("GET", "http://api.github.com"+path, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return req
+}
+
+func response(body string) *sawyer.CachedResponse {
+	header := make(http.Header)
+	header.Set("ETag", `"`+body+`"`)
+	return &sawyer.CachedResponse{StatusCode: 200, Header: header, Body: []byte(body)}
+}
+
+type TestUser struct {
+	Login string `json:"login"`
+}
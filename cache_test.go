@@ -0,0 +1,365 @@
+package sawyer
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCachedResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	cacher := newTestCacher()
+	setup.Client.Cacher = cacher
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, false, res.FromCache)
+	assert.Equal(t, 1, len(cacher.responses))
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user = &TestUser{}
+	res = req.Get()
+	assert.Equal(t, false, res.AnyError())
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, true, res.FromCache)
+	assert.Equal(t, `"abc"`, res.Header.Get("ETag"))
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "", req.Header.Get("If-None-Match"))
+}
+
+func TestNotModifiedResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("ETag", `"abc"`)
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	cacher := newTestCacher()
+	setup.Client.Cacher = cacher
+	setup.Client.RevalidateTransparently = false
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, false, res.NotModified())
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user = &TestUser{Login: "untouched"}
+	res = req.Get()
+	assert.Equal(t, false, res.AnyError())
+	assert.Equal(t, 304, res.StatusCode)
+	assert.Equal(t, true, res.NotModified())
+	assert.Equal(t, false, res.FromCache)
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "untouched", user.Login)
+	assert.Equal(t, false, cacher.responses[req.URL.String()].StoredAt.IsZero())
+}
+
+func TestUncachedResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get("If-None-Match"))
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	cacher := newTestCacher()
+	setup.Client.Cacher = cacher
+
+	for i := 0; i < 2; i++ {
+		req, err := setup.Client.NewRequest("user")
+		assert.Equal(t, nil, err)
+
+		user := &TestUser{}
+		res := req.Get()
+		assert.Equal(t, nil, res.Decode(user))
+		assert.Equal(t, "sawyer", user.Login)
+	}
+	assert.Equal(t, 0, len(cacher.responses))
+}
+
+func TestFreshCachedResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	setup.Client.clock = func() time.Time { return now }
+	setup.Client.Cacher = newTestCacher()
+
+	getUser := func() bool {
+		req, err := setup.Client.NewRequest("user")
+		assert.Equal(t, nil, err)
+
+		user := &TestUser{}
+		res := req.Get()
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, nil, res.Decode(user))
+		assert.Equal(t, "sawyer", user.Login)
+		return res.FromCache
+	}
+
+	assert.Equal(t, false, getUser())
+	now = now.Add(59 * time.Second)
+	assert.Equal(t, true, getUser())
+	assert.Equal(t, 1, requests)
+
+	now = now.Add(time.Second)
+	assert.Equal(t, false, getUser())
+	assert.Equal(t, 2, requests)
+}
+
+func TestExpiresCachedResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Date", date.Format(http.TimeFormat))
+		head.Set("Expires", date.Add(time.Hour).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	now := date
+	setup.Client.clock = func() time.Time { return now }
+	setup.Client.Cacher = newTestCacher()
+
+	for _, elapsed := range []time.Duration{0, 30 * time.Minute, time.Hour} {
+		now = date.Add(elapsed)
+		req, err := setup.Client.NewRequest("user")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 200, req.Get().StatusCode)
+	}
+	assert.Equal(t, 2, requests)
+}
+
+func TestCachedResponseFreshness(t *testing.T) {
+	stored := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cached := &CachedResponse{Header: make(http.Header), StoredAt: stored}
+	assert.Equal(t, false, cached.Fresh(stored))
+
+	cached.Header.Set("Cache-Control", "public, max-age=10")
+	assert.Equal(t, true, cached.Fresh(stored.Add(9*time.Second)))
+	assert.Equal(t, false, cached.Fresh(stored.Add(10*time.Second)))
+
+	cached.Header.Set("Age", "5")
+	assert.Equal(t, false, cached.Fresh(stored.Add(5*time.Second)))
+
+	cached.Header.Set("Cache-Control", "no-cache, max-age=10")
+	assert.Equal(t, false, cached.Fresh(stored))
+
+	assert.Equal(t, false, (*CachedResponse)(nil).Fresh(stored))
+}
+
+func TestCachedResponseExpiresWithoutDate(t *testing.T) {
+	stored := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cached := &CachedResponse{Header: make(http.Header), StoredAt: stored}
+	cached.Header.Set("Expires", stored.Add(time.Hour).Format(http.TimeFormat))
+
+	assert.Equal(t, true, cached.Fresh(stored.Add(59*time.Minute)))
+	assert.Equal(t, false, cached.Fresh(stored.Add(time.Hour)))
+
+	cached.Header.Set("Expires", "0")
+	assert.Equal(t, false, cached.Fresh(stored))
+}
+
+func TestUnstoredCacheControl(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	cacheControl := ""
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Cache-Control", cacheControl)
+		head.Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	tests := []struct {
+		cacheControl string
+		private      bool
+		stored       int
+	}{
+		{"no-store", false, 0},
+		{"no-store", true, 0},
+		{"private, max-age=60", false, 0},
+		{"private, max-age=60", true, 1},
+		{"public, max-age=60", false, 1},
+	}
+
+	for _, test := range tests {
+		cacheControl = test.cacheControl
+		cacher := newTestCacher()
+		setup.Client.Cacher = cacher
+		setup.Client.CachePrivateResponses = test.private
+
+		req, err := setup.Client.NewRequest("user")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 200, req.Get().StatusCode)
+		assert.Equal(t, test.stored, len(cacher.responses))
+	}
+}
+
+func TestUnstoredVaryStar(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("ETag", `"abc"`)
+		head.Set("Vary", "Accept, *")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cacher := newTestCacher()
+	setup.Client.Cacher = cacher
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, req.Get().StatusCode)
+	assert.Equal(t, 0, len(cacher.responses))
+}
+
+func TestLastModifiedCachedResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		assert.Equal(t, "", r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-Modified-Since") == modified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Last-Modified", modified)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	cacher := newTestCacher()
+	setup.Client.Cacher = cacher
+
+	for i := 0; i < 2; i++ {
+		req, err := setup.Client.NewRequest("user")
+		assert.Equal(t, nil, err)
+
+		user := &TestUser{}
+		res := req.Get()
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, modified, res.Header.Get("Last-Modified"))
+		assert.Equal(t, nil, res.Decode(user))
+		assert.Equal(t, "sawyer", user.Login)
+	}
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, len(cacher.responses))
+}
+
+func TestSetIfModifiedSince(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", r.Header.Get("If-Modified-Since"))
+		w.WriteHeader(http.StatusNotModified)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	req.SetIfModifiedSince(modified.In(time.FixedZone("PST", -8*60*60)))
+	assert.Equal(t, 304, req.Get().StatusCode)
+}
+
+type testCacher struct {
+	responses map[string]*CachedResponse
+	mutex     sync.Mutex
+}
+
+func newTestCacher() *testCacher {
+	return &testCacher{responses: make(map[string]*CachedResponse)}
+}
+
+func (c *testCacher) Get(req *http.Request) *CachedResponse {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.responses[req.URL.String()]
+}
+
+func (c *testCacher) Set(req *http.Request, res *CachedResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.responses[req.URL.String()] = res
+}
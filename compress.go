@@ -0,0 +1,148 @@
+package sawyer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// contentDecoders maps a Content-Encoding to a function that wraps a response
+// body with a decompressing reader.
+var contentDecoders = map[string]func(r io.Reader) (io.ReadCloser, error){
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": newDeflateReader,
+}
+
+// AddContentDecoder installs a decompressor for responses with the given
+// Content-Encoding, in addition to the built in gzip and deflate ones.  The
+// returned reader is closed along with the response body.
+//
+//	sawyer.AddContentDecoder("br", func(r io.Reader) (io.ReadCloser, error) {
+//		return ioutil.NopCloser(brotli.NewReader(r)), nil
+//	})
+func AddContentDecoder(encoding string, newReader func(r io.Reader) (io.ReadCloser, error)) {
+	contentDecoders[strings.ToLower(encoding)] = newReader
+}
+
+// SetAcceptEncoding sets the Accept-Encoding header sent with every Request
+// built by this Client, such as "identity" to ask for uncompressed responses.
+// Setting the header disables the transport's automatic gzip decompression,
+// so compressed responses are decompressed by this package instead, with the
+// decoders for gzip, deflate, and any added with AddContentDecoder.  Calling
+// it with no encodings restores the transport's default.
+func (c *Client) SetAcceptEncoding(encodings ...string) {
+	if len(encodings) == 0 {
+		c.Header.Del(acceptEncodingHeader)
+		return
+	}
+	c.Header.Set(acceptEncodingHeader, strings.Join(encodings, ", "))
+}
+
+// decompress replaces the body of a compressed response with a decompressing
+// reader.  The Content-Encoding header is removed so callers don't attempt to
+// decompress the body a second time.
+func decompress(res *http.Response) {
+	encoding := strings.ToLower(strings.TrimSpace(res.Header.Get(cencHeader)))
+	newReader, ok := contentDecoders[encoding]
+	if !ok {
+		return
+	}
+
+	res.Body = &decompressedBody{body: res.Body, newReader: newReader}
+	res.Header.Del(cencHeader)
+	res.Header.Del(clenHeader)
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
+
+// decompressedBody lazily builds its decompressor on the first Read, so that
+// Do doesn't block waiting for the compressed stream's header.  Closing it
+// closes both the decompressor and the original body.
+type decompressedBody struct {
+	body      io.ReadCloser
+	newReader func(r io.Reader) (io.ReadCloser, error)
+	reader    io.ReadCloser
+	err       error
+}
+
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.reader, b.err = b.newReader(b.body)
+	}
+
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+func (b *decompressedBody) Close() error {
+	var err error
+	if b.reader != nil {
+		err = b.reader.Close()
+	}
+
+	if berr := b.body.Close(); berr != nil {
+		return berr
+	}
+	return err
+}
+
+// newDeflateReader reads "deflate" bodies, which are supposed to be zlib
+// streams.  Some servers send raw deflate data instead, so the zlib header is
+// sniffed first.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	buf := bufio.NewReader(r)
+	header, err := buf.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	if header[0]&0x0f == 8 && (uint(header[0])<<8|uint(header[1]))%31 == 0 {
+		return zlib.NewReader(buf)
+	}
+	return flate.NewReader(buf), nil
+}
+
+// SetCompressedBody encodes the input like SetBody, and then gzips it.  A nil
+// input is sent as an empty gzip stream.
+func (r *Request) SetCompressedBody(mtype *mediatype.MediaType, input interface{}) error {
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+
+	if input != nil {
+		encoded, err := mtype.Encode(input)
+		if err != nil {
+			return err
+		}
+
+		if _, err := encoded.WriteTo(gz); err != nil {
+			return err
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	r.MediaType = mtype
+	r.Header.Set(ctypeHeader, mtype.String())
+	r.Header.Set(cencHeader, "gzip")
+	body := buf.Bytes()
+	r.ContentLength = int64(len(body))
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.buffered = true
+	return nil
+}
@@ -0,0 +1,171 @@
+package sawyer
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestGzipResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+		gz.Close()
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	// setting Accept-Encoding disables the transport's own decompression
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, "", res.Header.Get("Content-Encoding"))
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestDeflateResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Content-Encoding", "deflate")
+		w.WriteHeader(http.StatusOK)
+
+		zw := zlib.NewWriter(w)
+		zw.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+		zw.Close()
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, "", res.Header.Get("Content-Encoding"))
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestCompressedPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	mtype, err := mediatype.Parse("application/json")
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, mtype.String(), r.Header.Get("Content-Type"))
+
+		gz, err := gzip.NewReader(r.Body)
+		assert.Equal(t, nil, err)
+
+		user := &TestUser{}
+		mtype.Decode(user, gz)
+		assert.Equal(t, "sawyer", user.Login)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, req.SetCompressedBody(mtype, &TestUser{Login: "sawyer"}))
+	assert.NotEqual(t, int64(0), req.ContentLength)
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 201, res.StatusCode)
+}
+
+func TestCompressedEmptyPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	mtype, err := mediatype.Parse("application/json")
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		assert.Equal(t, nil, err)
+
+		body, err := ioutil.ReadAll(gz)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 0, len(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, req.SetCompressedBody(mtype, nil))
+	assert.NotEqual(t, nil, req.Body)
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 201, res.StatusCode)
+}
+
+func TestSetAcceptEncoding(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		if r.Header.Get("Accept-Encoding") == "identity" {
+			w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+			return
+		}
+
+		head.Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+		gz.Close()
+	})
+
+	client := setup.Client
+	client.SetAcceptEncoding("gzip", "deflate")
+	assert.Equal(t, "gzip, deflate", client.Header.Get("Accept-Encoding"))
+
+	req, err := client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, true, res.Uncompressed)
+
+	client.SetAcceptEncoding("identity")
+	req, err = client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user = &TestUser{}
+	res = req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, false, res.Uncompressed)
+
+	client.SetAcceptEncoding()
+	assert.Equal(t, "", client.Header.Get("Accept-Encoding"))
+}
@@ -0,0 +1,61 @@
+package sawyer
+
+import (
+	"io"
+	"net/http"
+)
+
+// DownloadTo sends a GET request for the resource from the resumeFrom offset
+// onwards, and writes the body to w at the matching offsets.  If the server
+// ignores the range and sends the whole resource, it is written from the
+// start, and the Response's Restarted field is set.  Error statuses return
+// the *ApiError from Response.Err.
+//
+//	f, _ := os.OpenFile("archive.tar", os.O_WRONLY|os.O_CREATE, 0644)
+//	info, _ := f.Stat()
+//	res, err := req.DownloadTo(f, info.Size())
+func (r *Request) DownloadTo(w io.WriterAt, resumeFrom int64) (*Response, error) {
+	if resumeFrom > 0 {
+		r.SetRange(resumeFrom, -1)
+	}
+
+	res, err := r.GetStream()
+	if err != nil {
+		return res, err
+	}
+
+	offset := int64(0)
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		offset = resumeFrom
+		if start, _, _, ok := res.ContentRange(); ok && start >= 0 {
+			offset = start
+		}
+	case http.StatusOK:
+		res.Restarted = resumeFrom > 0
+	default:
+		if err := res.Err(); err != nil {
+			return res, err
+		}
+		return res, res.Consume()
+	}
+
+	_, err = io.Copy(&offsetWriter{w, offset}, res.Body)
+	if cerr := res.Body.Close(); err == nil {
+		err = cerr
+	}
+	res.BodyClosed = true
+	return res, err
+}
+
+// offsetWriter writes sequentially to an io.WriterAt, starting at an offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
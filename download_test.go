@@ -0,0 +1,104 @@
+package sawyer
+
+import (
+	"bytes"
+	"errors"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadToResumes(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bytes=6-", r.Header.Get("Range"))
+		http.ServeContent(w, r, "archive", time.Time{}, strings.NewReader("sawyer-archive"))
+	})
+
+	req, err := setup.Client.NewRequest("archive")
+	assert.Equal(t, nil, err)
+
+	buf := &writerAt{data: []byte("sawyer")}
+	res, err := req.DownloadTo(buf, 6)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 206, res.StatusCode)
+	assert.Equal(t, false, res.Restarted)
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, "sawyer-archive", string(buf.data))
+}
+
+func TestDownloadToRestarts(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sawyer-archive"))
+	})
+
+	req, err := setup.Client.NewRequest("archive")
+	assert.Equal(t, nil, err)
+
+	buf := &writerAt{data: []byte("SAWYER")}
+	res, err := req.DownloadTo(buf, 6)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, true, res.Restarted)
+	assert.Equal(t, "sawyer-archive", string(buf.data))
+}
+
+func TestDownloadToApiError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	req, err := setup.Client.NewRequest("archive")
+	assert.Equal(t, nil, err)
+
+	buf := &writerAt{}
+	res, err := req.DownloadTo(buf, 0)
+	assert.Equal(t, "", req.Header.Get("Range"))
+	assert.Equal(t, "404 Not Found", err.Error())
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, 0, len(buf.data))
+}
+
+func TestDownloadToWriteError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sawyer-archive"))
+	})
+
+	req, err := setup.Client.NewRequest("archive")
+	assert.Equal(t, nil, err)
+
+	buf := &writerAt{err: errors.New("disk full")}
+	res, err := req.DownloadTo(buf, 0)
+	assert.Equal(t, "disk full", err.Error())
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+// writerAt is an in-memory io.WriterAt that grows as needed.
+type writerAt struct {
+	data []byte
+	err  error
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if end := int(off) + len(p); end > len(w.data) {
+		w.data = append(w.data, bytes.Repeat([]byte{0}, end-len(w.data))...)
+	}
+	return copy(w.data[off:], p), nil
+}
@@ -0,0 +1,152 @@
+package sawyer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An Event is a server-sent event from a text/event-stream response.  ID is
+// the last event ID sent by the server, which may come from an earlier event.
+type Event struct {
+	Event string
+	Data  string
+	ID    string
+	Retry time.Duration
+}
+
+// An EventScanner reads server-sent events from a Response.
+//
+//	events := res.EventStream()
+//	for {
+//		event, err := events.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+type EventScanner struct {
+	res     *Response
+	ctx     context.Context
+	scanner *bufio.Scanner
+	lastID  string
+	err     error
+}
+
+// EventStream returns an EventScanner for the response body.
+func (r *Response) EventStream() *EventScanner {
+	s := &EventScanner{res: r, ctx: context.Background()}
+	if r.Response != nil && r.Request != nil {
+		s.ctx = r.Request.Context()
+	}
+
+	switch {
+	case r.ResponseError != nil:
+		s.err = r.ResponseError
+	case r.BodyClosed:
+		s.err = io.EOF
+	default:
+		s.scanner = bufio.NewScanner(r.Body)
+		s.scanner.Split(scanEventLines)
+	}
+	return s
+}
+
+// Next returns the next event.  It returns io.EOF once the stream ends, or
+// the context's error if the request is cancelled.  The body is closed when
+// an error is returned.
+func (s *EventScanner) Next() (*Event, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	event := &Event{}
+	var data []string
+	for {
+		if err := s.ctx.Err(); err != nil {
+			return nil, s.fail(err)
+		}
+
+		if !s.scanner.Scan() {
+			err := s.scanner.Err()
+			if ctxerr := s.ctx.Err(); ctxerr != nil {
+				err = ctxerr
+			} else if err == nil {
+				err = io.EOF
+			}
+			return nil, s.fail(err)
+		}
+
+		line := s.scanner.Text()
+		if len(line) == 0 {
+			if data == nil {
+				event = &Event{}
+				continue
+			}
+
+			event.Data = strings.Join(data, "\n")
+			event.ID = s.lastID
+			return event, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				s.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+func (s *EventScanner) fail(err error) error {
+	s.err = err
+	s.res.Body.Close()
+	s.res.BodyClosed = true
+	return err
+}
+
+// scanEventLines splits lines ending with "\r\n", "\n", or "\r".
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+
+		// a "\r" at the end of the buffer may be followed by "\n"
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,83 @@
+package sawyer
+
+import (
+	"context"
+	"github.com/bmizerany/assert"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(": connected\n\n" +
+			"event: user\nid: 1\nretry: 3000\ndata: {\"login\":\ndata:  \"sawyer\"}\n\n" +
+			"data: second\r\n\r\n" +
+			"id\ndata:third\rdata\r\r" +
+			"data: incomplete"))
+	})
+
+	req, err := setup.Client.NewRequest("events")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	events := res.EventStream()
+
+	event, err := events.Next()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &Event{Event: "user", Data: "{\"login\":\n \"sawyer\"}", ID: "1", Retry: 3 * time.Second}, event)
+
+	event, err = events.Next()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &Event{Data: "second", ID: "1"}, event)
+
+	event, err = events.Next()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &Event{Data: "third\n"}, event)
+
+	event, err = events.Next()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, (*Event)(nil), event)
+	assert.Equal(t, true, res.BodyClosed)
+
+	_, err = events.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestEventStreamCancelled(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	done := make(chan bool)
+	setup.Mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	})
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := setup.Client.NewRequestWithContext(ctx, "events")
+	assert.Equal(t, nil, err)
+
+	events := req.Get().EventStream()
+	event, err := events.Next()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "first", event.Data)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = events.Next()
+	assert.Equal(t, context.Canceled, err)
+}
@@ -3,8 +3,12 @@
 package hypermedia
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/jtacoma/uritemplates"
+	"io"
+	"io/ioutil"
 	"net/url"
 	"reflect"
 )
@@ -34,7 +38,7 @@ func (l Hyperlink) Expand(m M) (*url.URL, error) {
 		return nil, err
 	}
 
-	return url.ParseRequestURI(expanded)
+	return url.Parse(expanded)
 }
 
 // M represents a map of values to expand a Hyperlink.
@@ -65,12 +69,17 @@ func HypermediaDecoder(res HypermediaResource) Relations {
 //
 // http://stateless.co/hal_specification.html
 type HALResource struct {
-	Links Links `json:"_links"`
-	rels  Relations
+	Links    Links    `json:"_links"`
+	Embedded Embedded `json:"_embedded"`
+	rels     Relations
 }
 
 // Rels gets the link relations from the HALResource's Links field.
 func (r *HALResource) Rels() Relations {
+	if r == nil {
+		return Relations{}
+	}
+
 	if r.rels == nil {
 		r.rels = make(map[string]Hyperlink)
 		for name, link := range r.Links {
@@ -80,6 +89,108 @@ func (r *HALResource) Rels() Relations {
 	return r.rels
 }
 
+// Embedded is a collection of embedded resources in a HALResource, keyed by
+// their relation name.  The resources are kept as raw JSON until decoded.
+type Embedded map[string]json.RawMessage
+
+// Decode decodes the embedded resource for the given relation name into v.
+func (e Embedded) Decode(name string, v interface{}) error {
+	raw, ok := e[name]
+	if !ok {
+		return fmt.Errorf("No %s embedded resource found", name)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// HALDecoder decodes an application/hal+json body into any value, while
+// keeping the _links and _embedded properties in its own HALResource.
+type HALDecoder struct {
+	Resource *HALResource
+	body     io.Reader
+
+	useNumber             bool
+	disallowUnknownFields bool
+}
+
+// NewHALDecoder returns a HALDecoder that reads from the given io.Reader.
+func NewHALDecoder(body io.Reader) *HALDecoder {
+	return &HALDecoder{body: body}
+}
+
+// Decode decodes the HAL body into v.
+func (d *HALDecoder) Decode(v interface{}) error {
+	body, err := ioutil.ReadAll(d.body)
+	if err != nil {
+		return err
+	}
+
+	d.Resource = &HALResource{}
+	if err := json.Unmarshal(body, d.Resource); err != nil {
+		return err
+	}
+
+	err = d.unmarshal(body, v)
+	for err != nil && d.disallowUnknownFields {
+		// the _links and _embedded properties are only unknown to v if it
+		// does not keep them itself
+		field := unknownHALField(err)
+		if len(field) == 0 {
+			break
+		}
+
+		var object map[string]json.RawMessage
+		if json.Unmarshal(body, &object) != nil {
+			break
+		}
+		delete(object, field)
+		if body, err = json.Marshal(object); err != nil {
+			return err
+		}
+		err = d.unmarshal(body, v)
+	}
+	return err
+}
+
+// UseNumber decodes numbers into interface{} values as json.Number instead
+// of float64, like the method of *json.Decoder.
+func (d *HALDecoder) UseNumber() {
+	d.useNumber = true
+}
+
+// DisallowUnknownFields fails to decode objects with fields that the output
+// struct does not have, other than the _links and _embedded properties, like
+// the method of *json.Decoder.
+func (d *HALDecoder) DisallowUnknownFields() {
+	d.disallowUnknownFields = true
+}
+
+func (d *HALDecoder) unmarshal(body []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if d.useNumber {
+		dec.UseNumber()
+	}
+	if d.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(v)
+}
+
+// unknownHALField returns the HAL property that an unknown field error is
+// about, if it is one.
+func unknownHALField(err error) string {
+	for _, field := range []string{"_links", "_embedded"} {
+		if err.Error() == fmt.Sprintf("json: unknown field %q", field) {
+			return field
+		}
+	}
+	return ""
+}
+
+// Rels gets the link relations from the decoded HAL body.
+func (d *HALDecoder) Rels() Relations {
+	return d.Resource.Rels()
+}
+
 // Links is a collection of Link objects in a HALResource.  Note that the HAL
 // spec allows single link objects or an array of link objects.  Sawyer
 // currently only supports single link objects.
@@ -57,6 +57,79 @@ func TestHALRelations(t *testing.T) {
 	assert.Equal(t, "/foo", rel.Path)
 }
 
+func TestHALEmbedded(t *testing.T) {
+	input := `
+{ "Login": "bob"
+, "_embedded":
+	{ "repos": [{ "Login": "repo1" }, { "Login": "repo2" }]
+	}
+}`
+
+	user := &HypermediaUser{}
+	decode(t, input, user)
+
+	repos := []HypermediaUser{}
+	assert.Equal(t, nil, user.Embedded.Decode("repos", &repos))
+	assert.Equal(t, 2, len(repos))
+	assert.Equal(t, "repo2", repos[1].Login)
+
+	err := user.Embedded.Decode("missing", &repos)
+	assert.Equal(t, "No missing embedded resource found", err.Error())
+}
+
+func TestHALDecoder(t *testing.T) {
+	input := `
+{ "Login": "bob"
+, "_links": { "self": { "href": "/self" } }
+, "_embedded": { "owner": { "Login": "alice" } }
+}`
+
+	user := &ReflectedUser{}
+	dec := NewHALDecoder(bytes.NewBufferString(input))
+	assert.Equal(t, nil, dec.Decode(user))
+	assert.Equal(t, "bob", user.Login)
+
+	rels := dec.Rels()
+	assert.Equal(t, 1, len(rels))
+	assert.Equal(t, "/self", string(rels["self"]))
+
+	owner := &ReflectedUser{}
+	assert.Equal(t, nil, dec.Resource.Embedded.Decode("owner", owner))
+	assert.Equal(t, "alice", owner.Login)
+}
+
+func TestHALDecoderOptions(t *testing.T) {
+	input := `{"id": 12345678901234567890, "_links": {"self": {"href": "/self"}}, "_embedded": {}}`
+
+	var v map[string]interface{}
+	dec := NewHALDecoder(bytes.NewBufferString(input))
+	dec.UseNumber()
+	assert.Equal(t, nil, dec.Decode(&v))
+	assert.Equal(t, json.Number("12345678901234567890"), v["id"])
+
+	user := &struct {
+		Id json.Number `json:"id"`
+	}{}
+	dec = NewHALDecoder(bytes.NewBufferString(input))
+	dec.DisallowUnknownFields()
+	assert.Equal(t, nil, dec.Decode(user))
+	assert.Equal(t, json.Number("12345678901234567890"), user.Id)
+	assert.Equal(t, "/self", string(dec.Rels()["self"]))
+
+	withLinks := &struct {
+		Id    json.Number `json:"id"`
+		Links Links       `json:"_links"`
+	}{}
+	dec = NewHALDecoder(bytes.NewBufferString(input))
+	dec.DisallowUnknownFields()
+	assert.Equal(t, nil, dec.Decode(withLinks))
+	assert.Equal(t, Hyperlink("/self"), withLinks.Links["self"].Href)
+
+	dec = NewHALDecoder(bytes.NewBufferString(`{"id": 1, "login": "sawyer"}`))
+	dec.DisallowUnknownFields()
+	assert.Equal(t, `json: unknown field "login"`, dec.Decode(user).Error())
+}
+
 func TestExpand(t *testing.T) {
 	link := Hyperlink("/foo/bar{/arg}")
 	u, err := link.Expand(M{"arg": "baz", "foo": "bar"})
@@ -64,6 +137,13 @@ func TestExpand(t *testing.T) {
 	assert.Equal(t, "/foo/bar/baz", u.String())
 }
 
+func TestExpandRelative(t *testing.T) {
+	link := Hyperlink("repos{/owner,repo}")
+	u, err := link.Expand(M{"owner": "lostisland", "repo": "sawyer"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "repos/lostisland/sawyer", u.String())
+}
+
 func TestExpandNil(t *testing.T) {
 	link := Hyperlink("/foo/bar{/arg}")
 	u, err := link.Expand(nil)
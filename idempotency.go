@@ -0,0 +1,54 @@
+package sawyer
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// SetIdempotencyKey sets the Idempotency-Key header, so that a server that
+// supports it, like Stripe's, does not repeat the request if it is retried.
+func (r *Request) SetIdempotencyKey(key string) {
+	r.Header.Set(idempotencyKeyHeader, key)
+}
+
+// withIdempotencyKey gives a POST or PATCH request that may be retried a
+// random key, if the Client's AutoIdempotencyKey option is set.  The key is set
+// on a copy of the request, so each call to Do sends a new key, and every
+// attempt within it is sent with the same one.
+func (r *Request) withIdempotencyKey(httpreq *http.Request) (*http.Request, error) {
+	if r.client == nil || !r.client.AutoIdempotencyKey || r.client.retry == nil {
+		return httpreq, nil
+	}
+
+	if httpreq.Method != PostMethod && httpreq.Method != PatchMethod {
+		return httpreq, nil
+	}
+
+	if len(httpreq.Header.Get(idempotencyKeyHeader)) > 0 {
+		return httpreq, nil
+	}
+
+	key, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	httpreq = httpreq.Clone(httpreq.Context())
+	httpreq.Header.Set(idempotencyKeyHeader, key)
+	return httpreq, nil
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+const idempotencyKeyHeader = "Idempotency-Key"
@@ -0,0 +1,121 @@
+package sawyer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A Logger traces the requests sent by a Client.  A *testing.T satisfies it.
+type Logger interface {
+	Logf(format string, args ...interface{})
+}
+
+// SetSensitiveFields sets the names of JSON fields, like "password" or
+// "token", whose values are replaced with "***" when request bodies are
+// logged.  Names are matched case-insensitively, at any depth.  The body that
+// is sent is not changed.
+func (c *Client) SetSensitiveFields(fields ...string) {
+	c.sensitive = make(map[string]bool, len(fields))
+	for _, field := range fields {
+		c.sensitive[strings.ToLower(field)] = true
+	}
+}
+
+func (c *Client) logStart(r *Request) {
+	if c.Logger == nil {
+		return
+	}
+
+	c.Logger.Logf("%s %s", r.Method, r.URL)
+	if c.LogRequestBodies {
+		if body, ok := loggableBody(r); ok {
+			c.Logger.Logf("%s %s body: %s", r.Method, r.URL, c.redact(body))
+		} else if r.streamed() {
+			c.Logger.Logf("%s %s body: %s", r.Method, r.URL, streamedBody)
+		}
+	}
+}
+
+func (c *Client) logFinish(r *Request, res *Response, start time.Time) {
+	if c.Logger == nil {
+		return
+	}
+
+	elapsed := time.Since(start)
+	if res.Response == nil {
+		c.Logger.Logf("%s %s failed in %s: %s", r.Method, r.URL, elapsed, res.Error())
+		return
+	}
+	c.Logger.Logf("%s %s %s in %s", r.Method, r.URL, res.Status, elapsed)
+}
+
+// loggableBody reads a copy of the request body, if it was buffered by
+// SetBody, SetRawBody, or SetFormBody.  Compressed bodies are skipped.
+func loggableBody(r *Request) ([]byte, bool) {
+	if !r.buffered || r.Body == nil || r.Body == http.NoBody || r.GetBody == nil || len(r.Header.Get(cencHeader)) > 0 {
+		return nil, false
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer body.Close()
+
+	buf, err := ioutil.ReadAll(body)
+	return buf, err == nil
+}
+
+// streamed reports whether the request has a body that is streamed from a
+// reader, which is not read for logging.
+func (r *Request) streamed() bool {
+	return !r.buffered && r.Body != nil && r.Body != http.NoBody
+}
+
+// redact replaces the values of sensitive fields in a JSON body.  Other
+// bodies are returned as is.
+func (c *Client) redact(body []byte) string {
+	if len(c.sensitive) == 0 {
+		return string(body)
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(c.redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+func (c *Client) redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if c.sensitive[strings.ToLower(key)] {
+				value[key] = redactedValue
+			} else {
+				value[key] = c.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = c.redactValue(item)
+		}
+	}
+	return v
+}
+
+const (
+	redactedValue = "***"
+	streamedBody  = "[streamed]"
+)
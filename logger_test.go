@@ -0,0 +1,118 @@
+package sawyer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	logger := &testLogger{}
+	setup.Client.Logger = logger
+
+	req, err := setup.Client.NewRequest("user?b=2")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+
+	url := setup.Server.URL + "/user?a=1&b=2"
+	assert.Equal(t, 2, len(logger.lines))
+	assert.Equal(t, "GET "+url, logger.lines[0])
+	assert.Tf(t, strings.HasPrefix(logger.lines[1], "GET "+url+" 204 No Content in "), "Bad log: %s", logger.lines[1])
+}
+
+func TestLoggerOnFailure(t *testing.T) {
+	setup := Setup(t)
+	setup.Teardown()
+
+	logger := &testLogger{}
+	setup.Client.Logger = logger
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, req.Get().IsError())
+
+	assert.Equal(t, 2, len(logger.lines))
+	assert.Tf(t, strings.Contains(logger.lines[1], "/user?a=1&b=1 failed in "), "Bad log: %s", logger.lines[1])
+}
+
+func TestLoggerRedactsSensitiveFields(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	payload := `{"login":"sawyer","password":"hunter2","keys":[{"Token":"abc"}]}`
+	setup.Mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Body", string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	logger := &testLogger{}
+	setup.Client.Logger = logger
+	setup.Client.LogRequestBodies = true
+	setup.Client.SetSensitiveFields("password", "token")
+
+	req, err := setup.Client.NewRequest("session")
+	assert.Equal(t, nil, err)
+	req.SetRawBody("application/json", []byte(payload))
+
+	res := req.Post()
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, payload, res.Header.Get("X-Body"))
+
+	url := setup.Server.URL + "/session?a=1&b=1"
+	assert.Equal(t, 3, len(logger.lines))
+	assert.Equal(t, "POST "+url+` body: {"keys":[{"Token":"***"}],"login":"sawyer","password":"***"}`, logger.lines[1])
+
+	req, err = setup.Client.NewRequest("session")
+	assert.Equal(t, nil, err)
+	req.SetRawBody("text/plain", []byte("not json"))
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, "POST "+url+" body: not json", logger.lines[4])
+}
+
+func TestLoggerSkipsStreamedBodies(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Body", string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	logger := &testLogger{}
+	setup.Client.Logger = logger
+	setup.Client.LogRequestBodies = true
+
+	req, err := setup.Client.NewRequest("upload")
+	assert.Equal(t, nil, err)
+	payload := []byte("streamed payload")
+	req.SetBodyReader("text/plain", bytes.NewReader(payload), int64(len(payload)))
+
+	res := req.Post()
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, "streamed payload", res.Header.Get("X-Body"))
+
+	url := setup.Server.URL + "/upload?a=1&b=1"
+	assert.Equal(t, 3, len(logger.lines))
+	assert.Equal(t, "POST "+url+" body: [streamed]", logger.lines[1])
+}
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Logf(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
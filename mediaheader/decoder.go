@@ -7,50 +7,120 @@ import (
 	"strings"
 )
 
-// TODO: need a full link header parser for http://tools.ietf.org/html/rfc5988
+// A Decoder parses hypermedia from response headers.  Link relations are
+// parsed from RFC 5988 Link headers: http://tools.ietf.org/html/rfc5988
 type Decoder struct {
 }
 
 func (d *Decoder) Decode(header http.Header) (mediaHeader *MediaHeader) {
 	mediaHeader = &MediaHeader{Relations: hypermedia.Relations{}}
 
-	link := header.Get("Link")
-	if len(link) == 0 {
-		return
+	for _, value := range header[http.CanonicalHeaderKey("Link")] {
+		for _, l := range parseLinks(value) {
+			url, err := url.Parse(l.href)
+			if err != nil {
+				continue
+			}
+
+			link := hypermedia.Hyperlink(url.String())
+			for _, rel := range strings.Fields(l.params["rel"]) {
+				mediaHeader.Relations[strings.ToLower(rel)] = link
+			}
+		}
 	}
 
-	for _, l := range strings.Split(link, ",") {
-		l = strings.TrimSpace(l)
-		segments := strings.Split(l, ";")
+	return
+}
 
-		if len(segments) < 2 {
-			continue
+type linkValue struct {
+	href   string
+	params map[string]string
+}
+
+// parseLinks splits a Link header into its link values.  Commas and semicolons
+// are allowed inside the <> brackets and inside quoted parameter values.
+func parseLinks(header string) []linkValue {
+	links := []linkValue{}
+	s := header
+
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if len(s) == 0 || s[0] != '<' {
+			return links
 		}
 
-		if !strings.HasPrefix(segments[0], "<") || !strings.HasSuffix(segments[0], ">") {
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return links
+		}
+
+		link := linkValue{href: s[1:end], params: make(map[string]string)}
+		s = parseLinkParams(s[end+1:], link.params)
+		links = append(links, link)
+	}
+}
+
+// parseLinkParams reads ";"-separated params into the given map, up to the
+// comma that ends the link value.  It returns the rest of the header.
+func parseLinkParams(s string, params map[string]string) string {
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if len(s) == 0 || s[0] == ',' {
+			return s
+		}
+
+		if s[0] != ';' {
+			// skip junk up to the next param or link
+			next := strings.IndexAny(s, ";,")
+			if next < 0 {
+				return ""
+			}
+			s = s[next:]
 			continue
 		}
 
-		url, err := url.Parse(segments[0][1 : len(segments[0])-1])
-		if err != nil {
+		s = strings.TrimLeft(s[1:], " \t")
+		nameEnd := strings.IndexAny(s, "=;,")
+		if nameEnd < 0 {
+			params[strings.ToLower(strings.TrimSpace(s))] = ""
+			return ""
+		}
+
+		name := strings.ToLower(strings.TrimSpace(s[:nameEnd]))
+		s = s[nameEnd:]
+		if s[0] != '=' {
+			params[name] = ""
 			continue
 		}
 
-		link := hypermedia.Hyperlink(url.String())
+		var value string
+		value, s = parseParamValue(strings.TrimLeft(s[1:], " \t"))
+		params[name] = value
+	}
+}
 
-		for _, segment := range segments[1:] {
-			switch strings.TrimSpace(segment) {
-			case `rel="next"`:
-				mediaHeader.Relations["next"] = link
-			case `rel="prev"`:
-				mediaHeader.Relations["prev"] = link
-			case `rel="first"`:
-				mediaHeader.Relations["first"] = link
-			case `rel="last"`:
-				mediaHeader.Relations["last"] = link
-			}
+func parseParamValue(s string) (string, string) {
+	if len(s) == 0 || s[0] != '"' {
+		end := strings.IndexAny(s, ";,")
+		if end < 0 {
+			return strings.TrimSpace(s), ""
 		}
+		return strings.TrimSpace(s[:end]), s[end:]
 	}
 
-	return
+	value := make([]byte, 0, len(s))
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				value = append(value, s[i])
+			}
+		case '"':
+			return string(value), s[i+1:]
+		default:
+			value = append(value, s[i])
+		}
+	}
+	return string(value), ""
 }
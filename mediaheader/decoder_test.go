@@ -16,3 +16,34 @@ func TestDecoder_Decode(t *testing.T) {
 	assert.Equal(t, "https://api.github.com/user/repos?page=3&per_page=100", string(mediaHeader.Relations["next"]))
 	assert.Equal(t, "https://api.github.com/user/repos?page=50&per_page=100", string(mediaHeader.Relations["last"]))
 }
+
+func TestDecoder_DecodeMultipleRels(t *testing.T) {
+	link := `<https://api.github.com/user/repos?page=50>; rel="next last"; title="a, b; c", </user/repos?page=1>;rel=first`
+	header := http.Header{}
+	header.Add("Link", link)
+	header.Add("Link", `<https://api.github.com/user>; REL="Self"`)
+	decoder := Decoder{}
+	mediaHeader := decoder.Decode(header)
+
+	assert.Equal(t, 4, len(mediaHeader.Relations))
+	assert.Equal(t, "https://api.github.com/user/repos?page=50", string(mediaHeader.Relations["next"]))
+	assert.Equal(t, "https://api.github.com/user/repos?page=50", string(mediaHeader.Relations["last"]))
+	assert.Equal(t, "/user/repos?page=1", string(mediaHeader.Relations["first"]))
+	assert.Equal(t, "https://api.github.com/user", string(mediaHeader.Relations["self"]))
+}
+
+func TestDecoder_DecodeCommaInUrl(t *testing.T) {
+	link := `<https://api.github.com/search?q=a,b>; rel="next"`
+	header := http.Header{}
+	header.Add("Link", link)
+	decoder := Decoder{}
+	mediaHeader := decoder.Decode(header)
+
+	assert.Equal(t, "https://api.github.com/search?q=a,b", string(mediaHeader.Relations["next"]))
+}
+
+func TestDecoder_DecodeWithoutLinks(t *testing.T) {
+	decoder := Decoder{}
+	mediaHeader := decoder.Decode(http.Header{})
+	assert.Equal(t, 0, len(mediaHeader.Relations))
+}
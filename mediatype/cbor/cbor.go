@@ -0,0 +1,28 @@
+// Package cbor registers a CBOR encoder and decoder for the "cbor" format,
+// matching "application/cbor", and types with a "+cbor" suffix like
+// "application/vnd.sawyer+cbor".  It is kept separate so that only clients
+// that need it import the cbor library:
+//
+//	import _ "github.com/lostisland/go-sawyer/mediatype/cbor"
+//
+// Struct fields are named by their "cbor" tags, or else their "json" tags, so
+// the same resource types can be used with JSON and CBOR APIs.
+package cbor
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
+)
+
+// Format is the mediatype Format that the codec is registered for.
+const Format = "cbor"
+
+func init() {
+	mediatype.AddDecoder(Format, func(r io.Reader) mediatype.Decoder {
+		return cbor.NewDecoder(r)
+	})
+	mediatype.AddEncoder(Format, func(w io.Writer) mediatype.Encoder {
+		return cbor.NewEncoder(w)
+	})
+}
@@ -0,0 +1,79 @@
+package cbor
+
+import (
+	"github.com/bmizerany/assert"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/lostisland/go-sawyer"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsesCborTypes(t *testing.T) {
+	for _, v := range []string{"application/cbor", "application/vnd.sawyer+cbor"} {
+		mt, err := mediatype.Parse(v)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Format, mt.Format)
+	}
+}
+
+func TestCborRoundTrip(t *testing.T) {
+	mtype, err := mediatype.Parse("application/cbor")
+	assert.Equal(t, nil, err)
+
+	buf, err := mtype.Encode(&TestUser{Id: 1, Login: "sawyer"})
+	assert.Equal(t, nil, err)
+
+	fields := map[string]interface{}{}
+	assert.Equal(t, nil, cbor.Unmarshal(buf.Bytes(), &fields))
+	assert.Equal(t, "sawyer", fields["login"])
+
+	user := &TestUser{}
+	assert.Equal(t, nil, mtype.Decode(user, buf))
+	assert.Equal(t, 1, user.Id)
+	assert.Equal(t, "sawyer", user.Login)
+}
+
+func TestCborPost(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.sawyer+cbor", r.Header.Get("Content-Type"))
+
+		user := &TestUser{}
+		assert.Equal(t, nil, cbor.NewDecoder(r.Body).Decode(user))
+		assert.Equal(t, "sawyer", user.Login)
+
+		w.Header().Set("Content-Type", "application/cbor")
+		w.WriteHeader(http.StatusCreated)
+		cbor.NewEncoder(w).Encode(&TestUser{Id: 2, Login: "sawyer2"})
+	})
+
+	client, err := sawyer.NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	mtype, err := mediatype.Parse("application/vnd.sawyer+cbor")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{Login: "sawyer"}
+	assert.Equal(t, nil, req.SetBody(mtype, user))
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, 2, user.Id)
+	assert.Equal(t, "sawyer2", user.Login)
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+type TestUser struct {
+	Id    int    `json:"id"`
+	Login string `json:"login"`
+}
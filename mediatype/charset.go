@@ -0,0 +1,87 @@
+package mediatype
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charset returns the lower case "charset" parameter, defaulting to "utf-8".
+func (m *MediaType) Charset() string {
+	if charset, ok := m.Params[charsetKey]; ok && len(charset) > 0 {
+		return strings.ToLower(charset)
+	}
+	return defaultCharset
+}
+
+// transcode converts a body in this MediaType's charset to UTF-8.  Bodies in
+// UTF-8, or in charsets that aren't known, are returned as is.
+func (m *MediaType) transcode(body io.Reader) io.Reader {
+	if table, ok := charsets[m.Charset()]; ok {
+		return &charsetReader{body: body, table: table}
+	}
+	return body
+}
+
+// charsetReader converts a single byte charset to UTF-8.
+type charsetReader struct {
+	body    io.Reader
+	table   func(b byte) rune
+	in      [512]byte
+	pending []byte
+	err     error
+}
+
+func (r *charsetReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		n, err := r.body.Read(r.in[:])
+		for _, b := range r.in[:n] {
+			r.pending = utf8.AppendRune(r.pending, r.table(b))
+		}
+		r.err = err
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func latin1(b byte) rune {
+	return rune(b)
+}
+
+func windows1252(b byte) rune {
+	if b >= 0x80 && b < 0xa0 {
+		return windows1252Table[b-0x80]
+	}
+	return rune(b)
+}
+
+// windows1252Table maps 0x80-0x9f, where Windows-1252 differs from ISO-8859-1.
+// Undefined bytes map to the matching C1 control characters.
+var windows1252Table = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+var charsets = map[string]func(b byte) rune{
+	"iso-8859-1":   latin1,
+	"iso8859-1":    latin1,
+	"iso_8859-1":   latin1,
+	"latin1":       latin1,
+	"l1":           latin1,
+	"windows-1252": windows1252,
+	"cp1252":       windows1252,
+	"x-cp1252":     windows1252,
+}
+
+const (
+	charsetKey     = "charset"
+	defaultCharset = "utf-8"
+)
@@ -0,0 +1,49 @@
+package mediatype
+
+import (
+	"bytes"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+func TestDefaultCharset(t *testing.T) {
+	m := Get(t, "application/json")
+	assert.Equal(t, "utf-8", m.Charset())
+
+	m = Get(t, "application/json; charset=UTF-8")
+	assert.Equal(t, "utf-8", m.Charset())
+}
+
+func TestDecodeLatin1(t *testing.T) {
+	m := Get(t, "application/test+test; charset=ISO-8859-1")
+	assert.Equal(t, "iso-8859-1", m.Charset())
+
+	person := &Person{}
+	err := m.Decode(person, bytes.NewBufferString("Jos\xe9 M\xfcller"))
+	if err != nil {
+		t.Fatalf("Error decoding: %s", err.Error())
+	}
+	assert.Equal(t, "José Müller", person.Name)
+}
+
+func TestDecodeWindows1252(t *testing.T) {
+	m := Get(t, "application/test+test; charset=windows-1252")
+
+	person := &Person{}
+	err := m.Decode(person, bytes.NewBufferString("\x93Jos\xe9\x94 \x80 5"))
+	if err != nil {
+		t.Fatalf("Error decoding: %s", err.Error())
+	}
+	assert.Equal(t, "“José” € 5", person.Name)
+}
+
+func TestDecodeUtf8(t *testing.T) {
+	m := Get(t, "application/test+test; charset=utf-8")
+
+	person := &Person{}
+	err := m.Decode(person, bytes.NewBufferString("José"))
+	if err != nil {
+		t.Fatalf("Error decoding: %s", err.Error())
+	}
+	assert.Equal(t, "José", person.Name)
+}
@@ -0,0 +1,52 @@
+package mediatype
+
+import (
+	"bytes"
+	"io"
+)
+
+// A Codec both encodes and decodes values for a format.
+type Codec interface {
+	Encode(v interface{}) (*bytes.Buffer, error)
+	Decode(v interface{}, r io.Reader) error
+}
+
+/*
+Register installs a Codec as both the encoder and decoder for a given format.
+
+	Register("msgpack", msgpackCodec)
+	mt, err := Parse("application/vnd.myapp+msgpack")
+	buf, err := mt.Encode(someValue)
+*/
+func Register(format string, codec Codec) {
+	AddDecoder(format, func(r io.Reader) Decoder {
+		return &codecDecoder{codec, r}
+	})
+	AddEncoder(format, func(w io.Writer) Encoder {
+		return &codecEncoder{codec, w}
+	})
+}
+
+type codecDecoder struct {
+	codec Codec
+	body  io.Reader
+}
+
+func (d *codecDecoder) Decode(v interface{}) error {
+	return d.codec.Decode(v, d.body)
+}
+
+type codecEncoder struct {
+	codec Codec
+	body  io.Writer
+}
+
+func (e *codecEncoder) Encode(v interface{}) error {
+	buf, err := e.codec.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(e.body)
+	return err
+}
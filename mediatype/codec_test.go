@@ -0,0 +1,62 @@
+package mediatype
+
+import (
+	"bytes"
+	"github.com/bmizerany/assert"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRegisterCodec(t *testing.T) {
+	mt, err := Parse("application/vnd.person+reverse")
+	if err != nil {
+		t.Fatalf("Error parsing media type: %s", err.Error())
+	}
+
+	buf, err := mt.Encode(&Person{"bob smith"})
+	if err != nil {
+		t.Fatalf("Error encoding: %s", err.Error())
+	}
+	assert.Equal(t, "htims bob", buf.String())
+
+	person := &Person{}
+	err = mt.Decode(person, buf)
+	if err != nil {
+		t.Fatalf("Error decoding: %s", err.Error())
+	}
+	assert.Equal(t, "bob smith", person.Name)
+}
+
+type ReverseCodec struct{}
+
+func (c *ReverseCodec) Encode(v interface{}) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	if p, ok := v.(*Person); ok {
+		buf.WriteString(reverse(p.Name))
+	}
+	return buf, nil
+}
+
+func (c *ReverseCodec) Decode(v interface{}, r io.Reader) error {
+	if p, ok := v.(*Person); ok {
+		by, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		p.Name = reverse(string(by))
+	}
+	return nil
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func init() {
+	Register("reverse", &ReverseCodec{})
+}
@@ -3,6 +3,8 @@ package mediatype
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 )
 
 var decoders = make(map[string]DecoderFunc)
@@ -16,7 +18,9 @@ type Decoder interface {
 }
 
 /*
-AddDecoder installs a decoder for a given format.
+AddDecoder installs a decoder for a given format.  A full media type like
+"application/hal+json" can be given instead, to override the format's decoder
+for that type only.
 
 	AddDecoder("json", func(r io.Reader) Encoder { return json.NewDecoder(r) })
 	mt, err := Parse("application/json")
@@ -26,13 +30,40 @@ func AddDecoder(format string, decfunc DecoderFunc) {
 	decoders[format] = decfunc
 }
 
-// Decoder finds a decoder based on this MediaType's Format field.  An error is
-// returned if a decoder cannot be found.
+// RegisteredFormats returns the sorted formats that have a decoder, like
+// "json" and "xml".  Decoders installed for a full media type are left out.
+func RegisteredFormats() []string {
+	formats := make([]string, 0, len(decoders))
+	for format, _ := range decoders {
+		if !strings.Contains(format, typeSplit) {
+			formats = append(formats, format)
+		}
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// CanDecode reports whether a decoder is installed for the given format, or
+// full media type.
+func CanDecode(format string) bool {
+	_, ok := decoders[format]
+	return ok
+}
+
+// Decoder finds a decoder based on this MediaType's Type, and then its Format
+// field.  An error listing the registered formats is returned if a decoder
+// cannot be found.  Bodies in a charset other than UTF-8 are converted to UTF-8
+// for the decoder.
 func (m *MediaType) Decoder(body io.Reader) (Decoder, error) {
+	if decfunc, ok := decoders[m.Type]; ok {
+		return decfunc(m.transcode(body)), nil
+	}
+
 	if decfunc, ok := decoders[m.Format]; ok {
-		return decfunc(body), nil
+		return decfunc(m.transcode(body)), nil
 	}
-	return nil, fmt.Errorf("No decoder found for format %s (%s)", m.Format, m.String())
+	return nil, fmt.Errorf("No decoder found for format %s (Content-Type: %s); supported: %s",
+		m.Format, m.String(), strings.Join(RegisteredFormats(), ", "))
 }
 
 // Encode uses this MediaType's Decoder to decode the io.Reader into the given
@@ -5,6 +5,7 @@ import (
 	"github.com/bmizerany/assert"
 	"io"
 	"io/ioutil"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -24,6 +25,33 @@ func TestAddDecoder(t *testing.T) {
 	assert.Equal(t, "bob", person.Name)
 }
 
+func TestAddDecoderForType(t *testing.T) {
+	buf := bytes.NewBufferString("bob")
+	mt, err := Parse("application/vnd.shout+test")
+	if err != nil {
+		t.Fatalf("Error parsing media type: %s", err.Error())
+	}
+
+	person := &Person{}
+	err = mt.Decode(person, buf)
+	if err != nil {
+		t.Fatalf("Error decoding: %s", err.Error())
+	}
+	assert.Equal(t, "BOB", person.Name)
+
+	mt, err = Parse("application/vnd.whisper+test")
+	if err != nil {
+		t.Fatalf("Error parsing media type: %s", err.Error())
+	}
+
+	person = &Person{}
+	err = mt.Decode(person, bytes.NewBufferString("bob"))
+	if err != nil {
+		t.Fatalf("Error decoding: %s", err.Error())
+	}
+	assert.Equal(t, "bob", person.Name)
+}
+
 func TestRequiresDecoder(t *testing.T) {
 	buf := bytes.NewBufferString("bob")
 	mt, err := Parse("application/test+whatevs")
@@ -40,6 +68,30 @@ func TestRequiresDecoder(t *testing.T) {
 	if !strings.HasPrefix(err.Error(), "No decoder found for format whatevs") {
 		t.Fatalf("Bad error: %s", err)
 	}
+
+	assert.Equal(t, true, strings.Contains(err.Error(), "(Content-Type: application/test+whatevs)"))
+	assert.Equal(t, true, strings.Contains(err.Error(), "; supported: "))
+	assert.Equal(t, true, strings.Contains(err.Error(), "test"))
+}
+
+func TestRegisteredFormats(t *testing.T) {
+	formats := RegisteredFormats()
+	assert.Equal(t, true, sort.StringsAreSorted(formats))
+	assert.Equal(t, true, contains(formats, "test"))
+	assert.Equal(t, false, contains(formats, "application/vnd.shout+test"))
+
+	assert.Equal(t, true, CanDecode("test"))
+	assert.Equal(t, true, CanDecode("application/vnd.shout+test"))
+	assert.Equal(t, false, CanDecode("whatevs"))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func TestSkipsDecoderForNil(t *testing.T) {
@@ -70,8 +122,23 @@ func (d *PersonDecoder) Decode(v interface{}) error {
 	return nil
 }
 
+type ShoutingPersonDecoder struct {
+	*PersonDecoder
+}
+
+func (d *ShoutingPersonDecoder) Decode(v interface{}) error {
+	err := d.PersonDecoder.Decode(v)
+	if p, ok := v.(*Person); ok {
+		p.Name = strings.ToUpper(p.Name)
+	}
+	return err
+}
+
 func init() {
 	AddDecoder("test", func(r io.Reader) Decoder {
 		return &PersonDecoder{r}
 	})
+	AddDecoder("application/vnd.shout+test", func(r io.Reader) Decoder {
+		return &ShoutingPersonDecoder{&PersonDecoder{r}}
+	})
 }
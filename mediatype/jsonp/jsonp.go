@@ -0,0 +1,96 @@
+// Package jsonp registers a decoder for JSON wrapped in a JSONP callback, like
+// `callback({"id": 1})`, for the "javascript" format.  It matches
+// "application/javascript", "text/javascript", and "application/x-javascript".
+// It is kept separate so that only clients of legacy JSONP APIs register it:
+//
+//	import _ "github.com/lostisland/go-sawyer/mediatype/jsonp"
+//
+// Any callback name is accepted, as well as plain JSON without a callback.
+package jsonp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
+	"io/ioutil"
+)
+
+// Format is the mediatype Format that the decoder is registered for.
+const Format = "javascript"
+
+// Decoder decodes the JSON inside a JSONP callback.
+type Decoder struct {
+	body io.Reader
+}
+
+// NewDecoder returns a Decoder that reads from the given io.Reader.
+func NewDecoder(body io.Reader) *Decoder {
+	return &Decoder{body}
+}
+
+// Decode strips the callback from the body, and decodes the JSON inside it
+// into v.
+func (d *Decoder) Decode(v interface{}) error {
+	body, err := ioutil.ReadAll(d.body)
+	if err != nil {
+		return err
+	}
+
+	inner, err := unwrap(body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(inner, v)
+}
+
+// unwrap returns the argument of the callback in a JSONP body.  A body that
+// is already JSON is returned as is.
+func unwrap(body []byte) ([]byte, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, io.EOF
+	}
+
+	// some APIs prefix the callback with an empty comment
+	body = bytes.TrimSpace(bytes.TrimPrefix(body, []byte("/**/")))
+
+	open := bytes.IndexByte(body, '(')
+	if open < 0 || !isCallback(body[:open]) {
+		return body, nil
+	}
+
+	body = bytes.TrimSpace(bytes.TrimSuffix(body, []byte(";")))
+	if !bytes.HasSuffix(body, []byte(")")) {
+		return nil, errInvalidJSONP
+	}
+	return body[open+1 : len(body)-1], nil
+}
+
+// isCallback reports whether name looks like a JavaScript function name, such
+// as "callback", "jQuery123_456", or "window.handlers.user".
+func isCallback(name []byte) bool {
+	name = bytes.TrimSpace(name)
+	if len(name) == 0 {
+		return false
+	}
+
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_' || c == '$' || c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var errInvalidJSONP = errors.New("Invalid JSONP response, the callback is not closed")
+
+func init() {
+	mediatype.AddDecoder(Format, func(r io.Reader) mediatype.Decoder {
+		return NewDecoder(r)
+	})
+}
@@ -0,0 +1,67 @@
+package jsonp
+
+import (
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParsesJavascriptTypes(t *testing.T) {
+	for _, v := range []string{"application/javascript", "text/javascript", "application/x-javascript"} {
+		mt, err := mediatype.Parse(v)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Format, mt.Format)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	bodies := []string{
+		`callback({"id": 1, "login": "sawyer"})`,
+		"\n  jQuery123_456 ( {\"id\": 1, \"login\": \"sawyer\"} ) ;\n",
+		`/**/ window.handlers.user({"id": 1, "login": "sawyer"});`,
+		`{"id": 1, "login": "sawyer"}`,
+	}
+
+	for _, body := range bodies {
+		user := &TestUser{}
+		assert.Equal(t, nil, NewDecoder(strings.NewReader(body)).Decode(user), body)
+		assert.Equal(t, "sawyer", user.Login, body)
+	}
+
+	user := &TestUser{}
+	err := NewDecoder(strings.NewReader(`callback({"id": 1}`)).Decode(user)
+	assert.Equal(t, "Invalid JSONP response, the callback is not closed", err.Error())
+}
+
+func TestJSONPGet(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		callback := r.URL.Query().Get("callback")
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Write([]byte(callback + `({"id": 2, "login": "sawyer2"});`))
+	})
+
+	client, err := sawyer.NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("users?callback=handleUsers")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, 2, user.Id)
+	assert.Equal(t, "sawyer2", user.Login)
+}
+
+type TestUser struct {
+	Id    int    `json:"id"`
+	Login string `json:"login"`
+}
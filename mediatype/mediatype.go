@@ -35,8 +35,11 @@ If it's not an "application/vnd" type, the Version field is taken from the
 "version" parameter.
 
 The Format is taken from the Suffix by default.  If not available, it is guessed
-by looking for common strings anywhere in the media type.  For instance,
-"application/json" will identify as the "json" Format.
+from a SubType that matches a format with a registered encoder or decoder, or by
+looking for common strings anywhere in the media type.  For instance,
+"application/x-msgpack" will identify as the "msgpack" Format if a msgpack
+decoder is registered, and "application/json" will identify as the "json"
+Format.
 
 The Format is used to get an Encoder and a Decoder.
 */
@@ -52,9 +55,20 @@ type MediaType struct {
 	Params   map[string]string
 }
 
-// Parse builds a *MediaType from a given media type string.
+// Parse builds a *MediaType from a given media type string.  Any number of
+// parameters are parsed into Params, with their names lowercased and quoted
+// values unquoted, and whitespace around the type and parameters is ignored:
+//
+//	mtype, _ := mediatype.Parse(`multipart/form-data; Charset=utf-8; boundary="a b"`)
+//	mtype.Params // map[boundary:a b charset:utf-8]
+//
+// Empty parameters, like a trailing ";", are skipped, and so are malformed
+// ones, like a name without a value, keeping the parameters around them.
 func Parse(v string) (*MediaType, error) {
 	mt, params, err := mime.ParseMediaType(v)
+	if err == mime.ErrInvalidMediaParameter {
+		mt, params, err = parseEachParam(v)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -66,17 +80,65 @@ func Parse(v string) (*MediaType, error) {
 	})
 }
 
-// String returns the full string representation of the MediaType.
+// String returns the full string representation of the MediaType, with its
+// parameters sorted by name, so it is stable however they were given.  A type
+// that cannot be formatted is returned as it was given to Parse.
 func (m *MediaType) String() string {
+	if s := mime.FormatMediaType(m.Type, m.Params); len(s) > 0 {
+		return s
+	}
 	return m.full
 }
 
+// SetParam sets a parameter, such as the charset, and updates the String()
+// representation with it.
+//
+//	mtype, _ := mediatype.Parse("application/json")
+//	mtype.SetParam("charset", "utf-8")
+//	mtype.String() // application/json; charset=utf-8
+func (m *MediaType) SetParam(key, value string) {
+	if m.Params == nil {
+		m.Params = make(map[string]string)
+	}
+	key = strings.ToLower(key)
+	m.Params[key] = value
+
+	if key == versionKey && !m.IsVendor() {
+		m.Version = value
+	}
+}
+
 // IsVendor determines if this MediaType is associated with commercially
 // available products.
 func (m *MediaType) IsVendor() bool {
 	return len(m.Vendor) > 0
 }
 
+// parseEachParam parses a media type whose parameters did not parse together,
+// one parameter at a time, skipping the ones that are empty or malformed.
+func parseEachParam(v string) (string, map[string]string, error) {
+	pieces := strings.Split(v, paramSplit)
+	mt, params, err := mime.ParseMediaType(pieces[0])
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, piece := range pieces[1:] {
+		if len(strings.TrimSpace(piece)) == 0 {
+			continue
+		}
+
+		_, param, err := mime.ParseMediaType(mt + paramSplit + piece)
+		if err != nil {
+			continue
+		}
+		for key, value := range param {
+			params[key] = value
+		}
+	}
+	return mt, params, nil
+}
+
 func parse(m *MediaType) (*MediaType, error) {
 	pieces := strings.Split(m.Type, typeSplit)
 	m.MainType = pieces[0]
@@ -114,6 +176,11 @@ func parse(m *MediaType) (*MediaType, error) {
 }
 
 func guessFormat(m *MediaType) {
+	if format := strings.TrimPrefix(m.SubType, extPrefix); isRegistered(format) {
+		m.Format = format
+		return
+	}
+
 	for _, fmt := range guessableTypes {
 		if strings.Contains(m.Type, fmt) {
 			m.Format = fmt
@@ -125,10 +192,20 @@ func guessFormat(m *MediaType) {
 const (
 	typeSplit   = "/"
 	suffixSplit = "+"
+	paramSplit  = ";"
 	versionKey  = "version"
 	vndPrefix   = "vnd."
 	vndLen      = 4
 	vndSplit    = "."
+	extPrefix   = "x-"
 )
 
 var guessableTypes = []string{"json", "xml"}
+
+func isRegistered(format string) bool {
+	if _, ok := decoders[format]; ok {
+		return true
+	}
+	_, ok := encoders[format]
+	return ok
+}
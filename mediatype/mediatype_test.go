@@ -85,12 +85,28 @@ func TestParamVersion(t *testing.T) {
 	assert.Equal(t, "utf-8", m.Params["charset"])
 	assert.Equal(t, "v2", m.Params["version"])
 }
+func TestRegisteredFormatSubtype(t *testing.T) {
+	m := Get(t, "application/reverse")
+	assert.Equal(t, "reverse", m.SubType)
+	assert.Equal(t, "reverse", m.Format)
+
+	m = Get(t, "application/x-reverse")
+	assert.Equal(t, "x-reverse", m.SubType)
+	assert.Equal(t, "reverse", m.Format)
+}
+
 func Get(t *testing.T, v string) *MediaType {
 	m, err := Parse(v)
 	if err != nil {
 		t.Fatalf("Errors parsing media type %s:\n%s", v, err.Error())
 	}
-	assert.Equal(t, v, m.String())
+
+	parsed, err := Parse(m.String())
+	if err != nil {
+		t.Fatalf("Errors parsing media type %s:\n%s", m.String(), err.Error())
+	}
+	assert.Equal(t, m.Type, parsed.Type)
+	assert.Equal(t, m.Params, parsed.Params)
 	return m
 }
 
@@ -98,3 +114,77 @@ func Get(t *testing.T, v string) *MediaType {
 type Person struct {
 	Name string
 }
+
+func TestStringKeepsParams(t *testing.T) {
+	m := Get(t, "application/json; charset=utf-8")
+	assert.Equal(t, "application/json; charset=utf-8", m.String())
+	assert.Equal(t, "utf-8", m.Params["charset"])
+}
+
+func TestSetParam(t *testing.T) {
+	m := Get(t, "application/json")
+	m.SetParam("Charset", "utf-8")
+	assert.Equal(t, "application/json; charset=utf-8", m.String())
+	assert.Equal(t, "utf-8", m.Params["charset"])
+
+	m.SetParam("version", "3")
+	assert.Equal(t, "application/json; charset=utf-8; version=3", m.String())
+	assert.Equal(t, "3", m.Version)
+
+	parsed := Get(t, m.String())
+	assert.Equal(t, m.Params, parsed.Params)
+}
+
+func TestParsesRealWorldParams(t *testing.T) {
+	tests := []struct {
+		v      string
+		mtype  string
+		params map[string]string
+	}{
+		{"application/json; charset=utf-8; boundary=xyz", "application/json",
+			map[string]string{"charset": "utf-8", "boundary": "xyz"}},
+		{"  application/json ;  charset=UTF-8 ", "application/json",
+			map[string]string{"charset": "UTF-8"}},
+		{"application/json;charset=utf-8", "application/json",
+			map[string]string{"charset": "utf-8"}},
+		{"text/html; Charset=ISO-8859-1", "text/html",
+			map[string]string{"charset": "ISO-8859-1"}},
+		{`multipart/form-data; boundary="----WebKitFormBoundary7MA4YWxkTrZu0gW"`, "multipart/form-data",
+			map[string]string{"boundary": "----WebKitFormBoundary7MA4YWxkTrZu0gW"}},
+		{`application/vnd.api+json; ext="https://jsonapi.org/ext/atomic"`, "application/vnd.api+json",
+			map[string]string{"ext": "https://jsonapi.org/ext/atomic"}},
+		{"application/json; charset=utf-8;", "application/json",
+			map[string]string{"charset": "utf-8"}},
+		{"application/json;; charset=utf-8", "application/json",
+			map[string]string{"charset": "utf-8"}},
+		{"application/json; charset", "application/json",
+			map[string]string{}},
+		{"text/plain; foo; charset=latin1", "text/plain",
+			map[string]string{"charset": "latin1"}},
+		{"text/plain; charset=latin1; =bar; format=flowed", "text/plain",
+			map[string]string{"charset": "latin1", "format": "flowed"}},
+	}
+
+	for _, test := range tests {
+		m := Get(t, test.v)
+		assert.Equalf(t, test.mtype, m.Type, "Bad type for %q", test.v)
+		assert.Equalf(t, test.params, m.Params, "Bad params for %q", test.v)
+	}
+}
+
+func TestStringSortsParams(t *testing.T) {
+	m := Get(t, "application/vnd.abc.v1+xml; version=v2; Charset=utf-8")
+	assert.Equal(t, "application/vnd.abc.v1+xml; charset=utf-8; version=v2", m.String())
+
+	m = Get(t, "text/plain; foo; charset=latin1")
+	assert.Equal(t, "text/plain; charset=latin1", m.String())
+}
+
+func TestSetParamSortsParams(t *testing.T) {
+	m := Get(t, "application/json; version=3; Charset=utf-8")
+	m.SetParam("boundary", "a b")
+	assert.Equal(t, `application/json; boundary="a b"; charset=utf-8; version=3`, m.String())
+
+	parsed := Get(t, m.String())
+	assert.Equal(t, m.Params, parsed.Params)
+}
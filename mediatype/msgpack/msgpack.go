@@ -0,0 +1,34 @@
+// Package msgpack registers a MessagePack encoder and decoder for the
+// "msgpack" format, matching "application/msgpack" and "application/x-msgpack".
+// It is kept separate so that only clients that need it import the msgpack
+// library:
+//
+//	import _ "github.com/lostisland/go-sawyer/mediatype/msgpack"
+//
+// Struct fields are named by their "json" tags, so the same resource types can
+// be used with JSON and MessagePack APIs.
+package msgpack
+
+import (
+	"github.com/lostisland/go-sawyer/mediatype"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+)
+
+// Format is the mediatype Format that the codec is registered for.
+const Format = "msgpack"
+
+func init() {
+	mediatype.AddDecoder(Format, func(r io.Reader) mediatype.Decoder {
+		dec := msgpack.NewDecoder(r)
+		dec.SetCustomStructTag(structTag)
+		return dec
+	})
+	mediatype.AddEncoder(Format, func(w io.Writer) mediatype.Encoder {
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag(structTag)
+		return enc
+	})
+}
+
+const structTag = "json"
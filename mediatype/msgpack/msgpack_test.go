@@ -0,0 +1,66 @@
+package msgpack
+
+import (
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsesMsgpackTypes(t *testing.T) {
+	for _, v := range []string{"application/msgpack", "application/x-msgpack", "application/vnd.sawyer+msgpack"} {
+		mt, err := mediatype.Parse(v)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Format, mt.Format)
+	}
+}
+
+func TestMsgpackPost(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-msgpack", r.Header.Get("Content-Type"))
+
+		user := &TestUser{}
+		dec := msgpack.NewDecoder(r.Body)
+		dec.SetCustomStructTag("json")
+		assert.Equal(t, nil, dec.Decode(user))
+		assert.Equal(t, "sawyer", user.Login)
+
+		w.Header().Set("Content-Type", "application/msgpack")
+		w.WriteHeader(http.StatusCreated)
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag("json")
+		enc.Encode(&TestUser{Id: 2, Login: "sawyer2"})
+	})
+
+	client, err := sawyer.NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	mtype, err := mediatype.Parse("application/x-msgpack")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{Login: "sawyer"}
+	assert.Equal(t, nil, req.SetBody(mtype, user))
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, 2, user.Id)
+	assert.Equal(t, "sawyer2", user.Login)
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+type TestUser struct {
+	Id    int    `json:"id"`
+	Login string `json:"login"`
+}
@@ -0,0 +1,64 @@
+package sawyer
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the http.RoundTripper that sends a Client's requests.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper, for writing
+// Middleware.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use adds middleware around the transport of the Client's HttpClient.  The
+// first middleware added sees each request first, and each response last.  A
+// middleware can skip the rest of the chain by returning a response itself.
+//
+//	client.Use(func(next http.RoundTripper) http.RoundTripper {
+//		return sawyer.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//			log.Println(req.Method, req.URL)
+//			return next.RoundTrip(req)
+//		})
+//	})
+//
+// HttpClient itself is not modified, since it may be shared.  Requests are
+// sent with a copy of it, made by NewRequest, whose Transport is wrapped with
+// the middleware.
+func (c *Client) Use(middleware ...func(http.RoundTripper) http.RoundTripper) {
+	for _, m := range middleware {
+		c.middleware = append(c.middleware, m)
+	}
+}
+
+// BeforeRequest adds a hook that runs before each request is sent.  If it
+// returns an error, the request is not sent and the error is returned in the
+// Response.
+func (c *Client) BeforeRequest(hook func(*Request) error) {
+	c.beforeRequest = append(c.beforeRequest, hook)
+}
+
+// AfterResponse adds a hook that runs with each Response, even if the request
+// failed.
+func (c *Client) AfterResponse(hook func(*Response)) {
+	c.afterResponse = append(c.afterResponse, hook)
+}
+
+// SetDecodeHook sets a hook that runs after a successful response is decoded,
+// with the value it was decoded into, such as to fill in computed fields.  If
+// it returns an error, the Response fails with it.  Error responses decoded
+// with Decode do not run the hook.
+//
+//	client.SetDecodeHook(func(res *sawyer.Response, output interface{}) error {
+//		if user, ok := output.(*User); ok {
+//			user.FetchedAt = time.Now()
+//		}
+//		return nil
+//	})
+func (c *Client) SetDecodeHook(hook func(res *Response, output interface{}) error) {
+	c.decodeHook = hook
+}
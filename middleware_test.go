@@ -0,0 +1,179 @@
+package sawyer
+
+import (
+	"bytes"
+	"errors"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"first", "second"}, r.Header.Values("X-Middleware"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				req.Header.Add("X-Middleware", name)
+				res, err := next.RoundTrip(req)
+				calls = append(calls, name+" response")
+				return res, err
+			})
+		}
+	}
+
+	setup.Client.Use(record("first"), record("second"))
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, []string{"first request", "second request", "second response", "first response"}, calls)
+	assert.Equal(t, nil, setup.Client.HttpClient.Transport)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request was not short-circuited")
+	})
+
+	setup.Client.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			header := make(http.Header)
+			header.Set("Content-Type", "application/json")
+			return &http.Response{
+				StatusCode:    200,
+				Header:        header,
+				Body:          ioutil.NopCloser(bytes.NewBufferString(`{"id": 1, "login": "sawyer"}`)),
+				ContentLength: -1,
+				Request:       req,
+			}, nil
+		})
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+}
+
+func TestRequestHooks(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sawyer", r.Header.Get("X-Hook"))
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var statuses []int
+	setup.Client.BeforeRequest(func(req *Request) error {
+		assert.Equal(t, GetMethod, req.Method)
+		req.Header.Set("X-Hook", "sawyer")
+		req.Query.Set("page", "2")
+		return nil
+	})
+	setup.Client.AfterResponse(func(res *Response) {
+		statuses = append(statuses, res.StatusCode)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, []int{204}, statuses)
+}
+
+func TestBeforeRequestHookError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request was not aborted")
+	})
+
+	hookErr := errors.New("not allowed")
+	setup.Client.BeforeRequest(func(req *Request) error {
+		return hookErr
+	})
+	setup.Client.BeforeRequest(func(req *Request) error {
+		t.Error("hook ran after an error")
+		return nil
+	})
+
+	var responses []*Response
+	setup.Client.AfterResponse(func(res *Response) {
+		responses = append(responses, res)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, hookErr, res.ResponseError)
+	assert.Equal(t, []*Response{res}, responses)
+}
+
+func TestDecodeHook(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Login", r.URL.Query().Get("login"))
+		if r.URL.Query().Get("login") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	calls := 0
+	setup.Client.SetDecodeHook(func(res *Response, output interface{}) error {
+		calls += 1
+		user := output.(*TestUser)
+		if res.Header.Get("X-Login") == "fail" {
+			return errors.New("bad user")
+		}
+		user.Login = strings.ToUpper(user.Login)
+		return nil
+	})
+
+	req, err := setup.Client.NewRequest("user?login=ok")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "SAWYER", user.Login)
+
+	req, err = setup.Client.NewRequest("user?login=fail")
+	assert.Equal(t, nil, err)
+
+	res = req.Get()
+	assert.Equal(t, "bad user", res.Decode(&TestUser{}).Error())
+	assert.Equal(t, true, res.IsError())
+
+	req, err = setup.Client.NewRequest("user?login=missing")
+	assert.Equal(t, nil, err)
+
+	res = req.Get()
+	assert.Equal(t, nil, res.Decode(&TestUser{}))
+	assert.Equal(t, 2, calls)
+}
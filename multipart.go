@@ -0,0 +1,91 @@
+package sawyer
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// SetMultipartBody streams a multipart/form-data body built from the given
+// form fields and files.  Each file is sent as a form file part named by its
+// key.  Its filename is taken from the reader's Name() method if it has one
+// (like an *os.File), or the key otherwise.  The body is written as it is
+// read, so nothing is started until the request is sent.
+func (r *Request) SetMultipartBody(fields map[string]string, files map[string]io.Reader) error {
+	for name, file := range files {
+		if file == nil {
+			return fmt.Errorf("No reader given for file %s", name)
+		}
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	body := &multipartBody{PipeReader: pr, write: func() {
+		pw.CloseWithError(writeMultipart(mw, fields, files))
+	}}
+
+	r.SetBodyReader(mw.FormDataContentType(), body, -1)
+	return nil
+}
+
+// multipartBody starts writing the multipart body on the first Read, so that
+// no goroutine is left behind if the request is never sent.
+type multipartBody struct {
+	*io.PipeReader
+	write func()
+	once  sync.Once
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.once.Do(func() { go b.write() })
+	return b.PipeReader.Read(p)
+}
+
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files map[string]io.Reader) error {
+	for _, name := range sortedKeys(fields) {
+		if err := mw.WriteField(name, fields[name]); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name, _ := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		file := files[name]
+		part, err := mw.CreateFormFile(name, multipartFilename(name, file))
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(part, file); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+func multipartFilename(name string, file io.Reader) string {
+	if named, ok := file.(interface {
+		Name() string
+	}); ok {
+		return filepath.Base(named.Name())
+	}
+	return name
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key, _ := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,107 @@
+package sawyer
+
+import (
+	"errors"
+	"github.com/bmizerany/assert"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMultipartPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Tf(t, strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data; boundary="), "Bad Content-Type: %s", r.Header.Get("Content-Type"))
+
+		assert.Equal(t, nil, r.ParseMultipartForm(1024))
+		assert.Equal(t, "sawyer", r.FormValue("login"))
+
+		file, header, err := r.FormFile("avatar")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "avatar", header.Filename)
+
+		body, err := ioutil.ReadAll(file)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "image data", string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("upload")
+	assert.Equal(t, nil, err)
+
+	err = req.SetMultipartBody(
+		map[string]string{"login": "sawyer"},
+		map[string]io.Reader{"avatar": strings.NewReader("image data")},
+	)
+	assert.Equal(t, nil, err)
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 201, res.StatusCode)
+}
+
+func TestMultipartPostWithBrokenFile(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("upload")
+	assert.Equal(t, nil, err)
+
+	err = req.SetMultipartBody(nil, map[string]io.Reader{"avatar": &brokenReader{}})
+	assert.Equal(t, nil, err)
+
+	res := req.Post()
+	assert.Equal(t, true, res.IsError())
+	assert.Tf(t, strings.Contains(res.Error(), "broken file"), "Bad error: %s", res.Error())
+}
+
+func TestMultipartPostWithoutReader(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	req, err := setup.Client.NewRequest("upload")
+	assert.Equal(t, nil, err)
+
+	err = req.SetMultipartBody(nil, map[string]io.Reader{"avatar": nil})
+	assert.Equal(t, "No reader given for file avatar", err.Error())
+}
+
+func TestMultipartBodyNotSent(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	aborted := errors.New("aborted")
+	setup.Client.BeforeRequest(func(r *Request) error {
+		return aborted
+	})
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		req, err := setup.Client.NewRequest("upload")
+		assert.Equal(t, nil, err)
+
+		err = req.SetMultipartBody(map[string]string{"name": "sawyer"}, map[string]io.Reader{"avatar": strings.NewReader("png")})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, aborted, req.Post().ResponseError)
+	}
+
+	// the writers only start once the bodies are read
+	assert.Tf(t, runtime.NumGoroutine() < before+10, "multipart writers were started")
+}
+
+type brokenReader struct{}
+
+func (r *brokenReader) Read(p []byte) (int, error) {
+	return 0, errors.New("broken file")
+}
@@ -0,0 +1,52 @@
+package sawyer
+
+import (
+	"encoding/json"
+)
+
+// A PatchOp is one operation of a JSON Patch document, as defined by RFC
+// 6902.  Value is only sent for the "add", "replace", and "test" operations,
+// even if it is nil, and From only if it is set.
+//
+//	req.SetJSONPatch([]sawyer.PatchOp{
+//		{Op: "replace", Path: "/login", Value: "sawyer"},
+//		{Op: "move", From: "/name", Path: "/full_name"},
+//	})
+type PatchOp struct {
+	Op    string
+	Path  string
+	Value interface{}
+	From  string
+}
+
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Op    string       `json:"op"`
+		Path  string       `json:"path"`
+		Value *interface{} `json:"value,omitempty"`
+		From  string       `json:"from,omitempty"`
+	}{Op: op.Op, Path: op.Path, From: op.From}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		out.Value = &op.Value
+	}
+	return json.Marshal(out)
+}
+
+// SetJSONPatch encodes the operations as an application/json-patch+json body,
+// for a PATCH request.
+func (r *Request) SetJSONPatch(ops []PatchOp) error {
+	return r.setBodyType(jsonPatchType, ops)
+}
+
+// SetMergePatch encodes the input as an application/merge-patch+json body, as
+// defined by RFC 7396, for a PATCH request.
+func (r *Request) SetMergePatch(input interface{}) error {
+	return r.setBodyType(mergePatchType, input)
+}
+
+const (
+	jsonPatchType  = "application/json-patch+json"
+	mergePatchType = "application/merge-patch+json"
+)
@@ -0,0 +1,61 @@
+package sawyer
+
+import (
+	"encoding/json"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestPatchOpJSON(t *testing.T) {
+	ops := []PatchOp{
+		{Op: "replace", Path: "/login", Value: "sawyer"},
+		{Op: "add", Path: "/email", Value: nil},
+		{Op: "remove", Path: "/id"},
+		{Op: "move", From: "/name", Path: "/full_name"},
+	}
+
+	data, err := json.Marshal(ops)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `[{"op":"replace","path":"/login","value":"sawyer"},`+
+		`{"op":"add","path":"/email","value":null},`+
+		`{"op":"remove","path":"/id"},`+
+		`{"op":"move","path":"/full_name","from":"/name"}]`, string(data))
+}
+
+func TestJSONPatch(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "application/json-patch+json", r.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `[{"op":"replace","path":"/login","value":"sawyer"}]`+"\n", string(body))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONPatch([]PatchOp{{Op: "replace", Path: "/login", Value: "sawyer"}}))
+	assert.Equal(t, 204, req.Patch().StatusCode)
+}
+
+func TestMergePatch(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"email":null,"login":"sawyer"}`+"\n", string(body))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetMergePatch(map[string]interface{}{"login": "sawyer", "email": nil}))
+	assert.Equal(t, 204, req.Patch().StatusCode)
+}
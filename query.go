@@ -0,0 +1,116 @@
+package sawyer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+SetQueryStruct merges the exported fields of a struct into the Request's Query.
+The parameter name is taken from a "url" struct tag, or the field name.  Nil
+pointers are skipped, slices become repeated parameters, and zero values are
+skipped if the tag has the "omitempty" option.  A nil value sets nothing.
+
+	type IssueOptions struct {
+	  State  string   `url:"state"`
+	  Labels []string `url:"labels,omitempty"`
+	  Page   *int     `url:"page"`
+	}
+*/
+func (r *Request) SetQueryStruct(v interface{}) error {
+	val := reflect.ValueOf(v)
+	if !val.IsValid() {
+		return nil
+	}
+
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("Unable to build query from %s, not a struct", val.Type())
+	}
+
+	t := val.Type()
+	fieldlen := t.NumField()
+	for i := 0; i < fieldlen; i++ {
+		if err := r.setQueryField(t.Field(i), val.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Request) setQueryField(f reflect.StructField, v reflect.Value) error {
+	if len(f.PkgPath) > 0 {
+		return nil
+	}
+
+	name, omitempty := queryTag(f)
+	if name == "-" || (omitempty && v.IsZero()) {
+		return nil
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		value, err := queryValue(name, v)
+		if err != nil {
+			return err
+		}
+		r.Query.Set(name, value)
+		return nil
+	}
+
+	r.Query.Del(name)
+	for i := 0; i < v.Len(); i++ {
+		value, err := queryValue(name, v.Index(i))
+		if err != nil {
+			return err
+		}
+		r.Query.Add(name, value)
+	}
+	return nil
+}
+
+func queryTag(f reflect.StructField) (string, bool) {
+	tag := strings.Split(f.Tag.Get("url"), ",")
+	name := tag[0]
+	if len(name) == 0 {
+		name = f.Name
+	}
+
+	for _, opt := range tag[1:] {
+		if opt == "omitempty" {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+func queryValue(name string, v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), nil
+	}
+	return "", fmt.Errorf("Unable to encode %s query parameter of type %s", name, v.Type())
+}
@@ -0,0 +1,75 @@
+package sawyer
+
+import (
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+func TestSetQueryStruct(t *testing.T) {
+	client, err := NewFromString("http://api.github.com?a=1", nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("issues?state=closed")
+	assert.Equal(t, nil, err)
+
+	page := 2
+	opts := &TestIssueOptions{
+		State:  "open",
+		Labels: []string{"bug", "ui"},
+		Page:   &page,
+		hidden: "secret",
+	}
+	assert.Equal(t, nil, req.SetQueryStruct(opts))
+
+	assert.Equal(t, "1", req.Query.Get("a"))
+	assert.Equal(t, "open", req.Query.Get("state"))
+	assert.Equal(t, []string{"bug", "ui"}, req.Query["labels"])
+	assert.Equal(t, "2", req.Query.Get("page"))
+	assert.Equal(t, "false", req.Query.Get("Mine"))
+	assert.Equal(t, "", req.Query.Get("sort"))
+	assert.Equal(t, "", req.Query.Get("hidden"))
+	assert.Equal(t, "", req.Query.Get("Ignored"))
+}
+
+func TestSetQueryStructSkipsNilPointers(t *testing.T) {
+	client, err := NewFromString("http://api.github.com", nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("issues")
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, req.SetQueryStruct(&TestIssueOptions{}))
+	_, ok := req.Query["page"]
+	assert.Equal(t, false, ok)
+	_, ok = req.Query["labels"]
+	assert.Equal(t, false, ok)
+	_, ok = req.Query["state"]
+	assert.Equal(t, true, ok)
+
+	req, err = client.NewRequest("issues")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetQueryStruct(nil))
+	assert.Equal(t, nil, req.SetQueryStruct((*TestIssueOptions)(nil)))
+	assert.Equal(t, 0, len(req.Query))
+}
+
+func TestSetQueryStructRequiresStruct(t *testing.T) {
+	client, err := NewFromString("http://api.github.com", nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("issues")
+	assert.Equal(t, nil, err)
+
+	err = req.SetQueryStruct("state=open")
+	assert.Equal(t, "Unable to build query from string, not a struct", err.Error())
+}
+
+type TestIssueOptions struct {
+	State   string   `url:"state"`
+	Labels  []string `url:"labels,omitempty"`
+	Sort    string   `url:"sort,omitempty"`
+	Page    *int     `url:"page"`
+	Mine    bool
+	Ignored string `url:"-"`
+	hidden  string
+}
@@ -0,0 +1,78 @@
+package sawyer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetRange requests the bytes from start to end, inclusive.  A negative end
+// requests everything from start to the end of the resource.  A server that
+// supports ranges responds with 206 Partial Content.
+func (r *Request) SetRange(start, end int64) {
+	if end < 0 {
+		r.Header.Set(rangeHeader, fmt.Sprintf("bytes=%d-", start))
+	} else {
+		r.Header.Set(rangeHeader, fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+}
+
+// ContentRange parses the Content-Range header of a partial response.  The
+// end is inclusive.  The total is -1 if the server does not know the size of
+// the resource.  For a 416 Range Not Satisfiable response of the form
+// "bytes */total", start and end are -1.
+func (r *Response) ContentRange() (start, end, total int64, ok bool) {
+	if r.Response == nil {
+		return 0, 0, 0, false
+	}
+	return parseContentRange(r.Header.Get(contentRangeHeader))
+}
+
+func parseContentRange(value string) (start, end, total int64, ok bool) {
+	fail := func() (int64, int64, int64, bool) { return 0, 0, 0, false }
+
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "bytes ") {
+		return fail()
+	}
+
+	slash := strings.IndexByte(value, '/')
+	if slash < 0 {
+		return fail()
+	}
+	span, size := strings.TrimSpace(value[6:slash]), value[slash+1:]
+
+	var err error
+	if size == "*" {
+		total = -1
+	} else if total, err = strconv.ParseInt(size, 10, 64); err != nil || total < 0 {
+		return fail()
+	}
+
+	if span == "*" {
+		if total < 0 {
+			return fail()
+		}
+		return -1, -1, total, true
+	}
+
+	dash := strings.IndexByte(span, '-')
+	if dash < 0 {
+		return fail()
+	}
+	if start, err = strconv.ParseInt(span[:dash], 10, 64); err != nil || start < 0 {
+		return fail()
+	}
+	if end, err = strconv.ParseInt(span[dash+1:], 10, 64); err != nil || end < start {
+		return fail()
+	}
+	if total >= 0 && end >= total {
+		return fail()
+	}
+	return start, end, total, true
+}
+
+const (
+	rangeHeader        = "Range"
+	contentRangeHeader = "Content-Range"
+)
@@ -0,0 +1,74 @@
+package sawyer
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"testing"
+)
+
+func TestSetRange(t *testing.T) {
+	client, err := NewFromString("https://api.github.com", nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("archive")
+	assert.Equal(t, nil, err)
+
+	req.SetRange(0, 99)
+	assert.Equal(t, "bytes=0-99", req.Header.Get("Range"))
+
+	req.SetRange(100, -1)
+	assert.Equal(t, "bytes=100-", req.Header.Get("Range"))
+}
+
+func TestPartialContent(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bytes=2-5", r.Header.Get("Range"))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Range", "bytes 2-5/10")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("2345"))
+	})
+
+	req, err := setup.Client.NewRequest("archive")
+	assert.Equal(t, nil, err)
+
+	req.SetRange(2, 5)
+	res := req.Get()
+	assert.Equal(t, 206, res.StatusCode)
+	assert.Equal(t, false, res.AnyError())
+
+	start, end, total, ok := res.ContentRange()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, int64(2), start)
+	assert.Equal(t, int64(5), end)
+	assert.Equal(t, int64(10), total)
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		value             string
+		start, end, total int64
+		ok                bool
+	}{
+		{"bytes 0-99/1000", 0, 99, 1000, true},
+		{"bytes 100-199/*", 100, 199, -1, true},
+		{"bytes */1000", -1, -1, 1000, true},
+		{"bytes */*", 0, 0, 0, false},
+		{"bytes 5-1/10", 0, 0, 0, false},
+		{"bytes 0-10/10", 0, 0, 0, false},
+		{"items 0-9/10", 0, 0, 0, false},
+		{"bytes 0-9", 0, 0, 0, false},
+		{"", 0, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		start, end, total, ok := parseContentRange(test.value)
+		assert.Equal(t, test.ok, ok, test.value)
+		assert.Equal(t, test.start, start, test.value)
+		assert.Equal(t, test.end, end, test.value)
+		assert.Equal(t, test.total, total, test.value)
+	}
+}
@@ -0,0 +1,101 @@
+package sawyer
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit parses the X-RateLimit-Limit, X-RateLimit-Remaining, and
+// X-RateLimit-Reset headers.  The reset is a Unix timestamp.  It returns false
+// if any of the headers are missing or invalid.
+func (r *Response) RateLimit() (limit, remaining int, reset time.Time, ok bool) {
+	if r.Response == nil {
+		return
+	}
+	return rateLimit(r.Header)
+}
+
+func rateLimit(header http.Header) (limit, remaining int, reset time.Time, ok bool) {
+	limit, err := strconv.Atoi(header.Get(rateLimitHeader))
+	if err != nil {
+		return 0, 0, reset, false
+	}
+
+	remaining, err = strconv.Atoi(header.Get(rateRemainingHeader))
+	if err != nil {
+		return 0, 0, reset, false
+	}
+
+	secs, err := strconv.ParseInt(header.Get(rateResetHeader), 10, 64)
+	if err != nil {
+		return 0, 0, reset, false
+	}
+	return limit, remaining, time.Unix(secs, 0), true
+}
+
+// throttle waits until the rate limit resets, if the last response reported
+// that no requests remain.
+func (c *Client) throttle(ctx context.Context) error {
+	reset, ok := c.rate.exhausted()
+	if !ok {
+		return nil
+	}
+
+	if wait := reset.Sub(c.now()); wait > 0 {
+		return sleep(ctx, wait)
+	}
+	return nil
+}
+
+// rateLimitState tracks the rate limit from the most recent response.
+type rateLimitState struct {
+	remaining int
+	reset     time.Time
+	known     bool
+	mutex     sync.Mutex
+}
+
+func (s *rateLimitState) update(res *http.Response) {
+	if s == nil {
+		return
+	}
+
+	_, remaining, reset, ok := rateLimit(res.Header)
+	if !ok {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.remaining, s.reset, s.known = remaining, reset, true
+}
+
+func (s *rateLimitState) clone() *rateLimitState {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return &rateLimitState{remaining: s.remaining, reset: s.reset, known: s.known}
+}
+
+// exhausted returns the reset time if no requests remain.
+func (s *rateLimitState) exhausted() (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.reset, s.known && s.remaining <= 0
+}
+
+const (
+	rateLimitHeader     = "X-RateLimit-Limit"
+	rateRemainingHeader = "X-RateLimit-Remaining"
+	rateResetHeader     = "X-RateLimit-Reset"
+)
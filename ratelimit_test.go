@@ -0,0 +1,121 @@
+package sawyer
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("X-RateLimit-Limit", "5000")
+		head.Set("X-RateLimit-Remaining", "4999")
+		head.Set("X-RateLimit-Reset", "1372700873")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	limit, remaining, reset, ok := req.Get().RateLimit()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 5000, limit)
+	assert.Equal(t, 4999, remaining)
+	assert.Equal(t, int64(1372700873), reset.Unix())
+}
+
+func TestMissingRateLimit(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	limit, remaining, reset, ok := req.Get().RateLimit()
+	assert.Equal(t, false, ok)
+	assert.Equal(t, 0, limit)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, true, reset.IsZero())
+
+	_, _, _, ok = ResponseError(nil).RateLimit()
+	assert.Equal(t, false, ok)
+}
+
+func TestThrottleOnRateLimit(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	reset := time.Unix(1372700873, 0)
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		head := w.Header()
+		head.Set("X-RateLimit-Limit", "5000")
+		head.Set("X-RateLimit-Remaining", "0")
+		head.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.ThrottleOnRateLimit = true
+	setup.Client.clock = func() time.Time { return reset.Add(-200 * time.Millisecond) }
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+
+	start := time.Now()
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, true, time.Since(start) >= 200*time.Millisecond)
+	assert.Equal(t, 2, requests)
+
+	// a reset in the past does not wait
+	setup.Client.clock = func() time.Time { return reset.Add(time.Hour) }
+
+	start = time.Now()
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, true, time.Since(start) < 200*time.Millisecond)
+}
+
+func TestThrottleOnRateLimitCancelled(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	reset := time.Unix(1372700873, 0)
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("X-RateLimit-Limit", "5000")
+		head.Set("X-RateLimit-Remaining", "0")
+		head.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.ThrottleOnRateLimit = true
+	setup.Client.clock = func() time.Time { return reset.Add(-time.Hour) }
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	req.SetTimeout(10 * time.Millisecond)
+
+	res := req.Get()
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, "Request timed out after 10ms", res.Error())
+}
@@ -0,0 +1,65 @@
+package sawyer
+
+import (
+	"errors"
+	"net/http"
+)
+
+// NoRedirects is a redirect policy that returns 3xx responses as is, with
+// their Location header, instead of following them.
+func NoRedirects(req *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// FollowRedirects returns a redirect policy that follows up to max redirects,
+// and then returns the last 3xx response.
+func FollowRedirects(max int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > max {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+}
+
+// SetRedirectPolicy sets the CheckRedirect function used for requests built by
+// this Client, without changing HttpClient.  The Authorization and Cookie
+// headers are not sent along redirects to another host, unless the Client's
+// AllowCrossHostAuth is set.
+//
+//	client.SetRedirectPolicy(sawyer.NoRedirects)
+func (c *Client) SetRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) {
+	c.redirect = policy
+}
+
+// checkRedirect wraps a redirect policy, defaulting to net/http's limit of 10
+// redirects, to control which credentials follow a redirect.
+func (c *Client) checkRedirect(policy func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if policy != nil {
+			if err := policy(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		orig := via[0]
+		if req.URL.Host == orig.URL.Host && req.URL.Scheme == orig.URL.Scheme {
+			return nil
+		}
+
+		for _, key := range []string{authHeader, cookieHeader} {
+			if c.AllowCrossHostAuth {
+				if value, ok := orig.Header[key]; ok {
+					req.Header[key] = value
+				}
+			} else {
+				req.Header.Del(key)
+			}
+		}
+		return nil
+	}
+}
+
+const cookieHeader = "Cookie"
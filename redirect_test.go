@@ -0,0 +1,142 @@
+package sawyer
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNoRedirects(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+
+	setup.Mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect was followed")
+	})
+
+	setup.Client.SetRedirectPolicy(NoRedirects)
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 302, res.StatusCode)
+	assert.Equal(t, "/login", res.Header.Get("Location"))
+	assert.Equal(t, "/user", res.FinalURL.Path)
+	assert.Equal(t, (func(*http.Request, []*http.Request) error)(nil), setup.Client.HttpClient.CheckRedirect)
+}
+
+func TestFollowRedirects(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/1", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/2", http.StatusFound)
+	})
+
+	setup.Mux.HandleFunc("/2", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/3", http.StatusFound)
+	})
+
+	setup.Mux.HandleFunc("/3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.SetRedirectPolicy(FollowRedirects(1))
+	req, err := setup.Client.NewRequest("1")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, 302, res.StatusCode)
+	assert.Equal(t, "/3", res.Header.Get("Location"))
+	assert.Equal(t, "/2", res.FinalURL.Path)
+
+	setup.Client.SetRedirectPolicy(FollowRedirects(2))
+	req, err = setup.Client.NewRequest("1")
+	assert.Equal(t, nil, err)
+
+	res = req.Get()
+	assert.Equal(t, 204, res.StatusCode)
+	assert.Equal(t, "/3", res.FinalURL.Path)
+}
+
+func TestRedirectToOtherHostDropsAuth(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get("Authorization"))
+		assert.Equal(t, "", r.Header.Get("Cookie"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		assert.Equal(t, "session=1", r.Header.Get("Cookie"))
+		http.Redirect(w, r, strings.Replace(other.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+	})
+
+	setup.Client.SetBearerToken("abc")
+	setup.Client.Header.Set("Cookie", "session=1")
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+
+	setup.Client.SetRedirectPolicy(FollowRedirects(5))
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+}
+
+func TestRedirectToSameHostKeepsAuth(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/users/sawyer", http.StatusFound)
+	})
+
+	setup.Mux.HandleFunc("/users/sawyer", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.SetBearerToken("abc")
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+}
+
+func TestAllowCrossHostAuth(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		assert.Equal(t, "session=1", r.Header.Get("Cookie"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(other.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+	})
+
+	setup.Client.SetBearerToken("abc")
+	setup.Client.Header.Set("Cookie", "session=1")
+	setup.Client.AllowCrossHostAuth = true
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+}
@@ -1,52 +1,415 @@
 package sawyer
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lostisland/go-sawyer/hypermedia"
 	"github.com/lostisland/go-sawyer/mediaheader"
 	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"reflect"
+	"strings"
+	"time"
 )
 
 type Request struct {
 	Client    *http.Client
 	MediaType *mediatype.MediaType
 	Query     url.Values
+	timeout   time.Duration
+	client    *Client
+
+	urlQuery     url.Values
+	defaultQuery url.Values
+	stream       bool
+	bodyCloser   io.Closer
+	rewind       func() error
+	expect       []int
+	generation   uint64
+	buffered     bool
 	*http.Request
 }
 
+// NewRequest builds a GET Request for the given URL.  A relative URL, like
+// "users/sawyer", is resolved against the Client's Endpoint.  An absolute URL,
+// like a link to another host, is used as is, with only the default query
+// parameters merged in.
 func (c *Client) NewRequest(rawurl string) (*Request, error) {
-	u, err := c.ResolveReferenceString(rawurl)
+	return c.NewRequestWithContext(context.Background(), rawurl)
+}
+
+// NewRequestWithContext builds a Request like NewRequest, bound to the given
+// context.  Cancelling the context aborts the request.
+func (c *Client) NewRequestWithContext(ctx context.Context, rawurl string) (*Request, error) {
+	ref, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	httpreq, err := http.NewRequestWithContext(ctx, GetMethod, c.ResolveReference(ref).String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	httpreq, err := http.NewRequest(GetMethod, u, nil)
 	for key, _ := range c.Header {
 		httpreq.Header.Set(key, c.Header.Get(key))
 	}
 
-	return &Request{c.HttpClient, nil, httpreq.URL.Query(), httpreq}, err
+	req := &Request{Client: c.httpClient(), Query: httpreq.URL.Query(), client: c, Request: httpreq}
+	req.urlQuery = ref.Query()
+	req.defaultQuery = c.cloneQuery()
+	req.setCredentials()
+	return req, nil
+}
+
+// NewRequestPath builds a Request for the path made of the given segments,
+// relative to the Client's Endpoint.  Each segment is escaped, so slashes and
+// other special characters in it stay part of the segment, and "." or ".."
+// segments do not move up the path.  Empty segments are skipped.
+//
+//	req, err := client.NewRequestPath("repos", owner, repo, "issues")
+func (c *Client) NewRequestPath(segments ...string) (*Request, error) {
+	escaped := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch segment {
+		case "":
+		case ".", "..":
+			// dot segments would otherwise move up the path
+			escaped = append(escaped, strings.Replace(segment, ".", "%2E", -1))
+		default:
+			escaped = append(escaped, url.PathEscape(segment))
+		}
+	}
+
+	// the leading "./" keeps a colon in the first segment from being read as
+	// a scheme
+	return c.NewRequest("./" + strings.Join(escaped, "/"))
+}
+
+// NewRequestTemplate expands a RFC 6570 URI template with the given params, and
+// builds a Request for the resulting URL.  Variables missing from params are
+// dropped from the expansion.
+//
+//	req, err := client.NewRequestTemplate("repos/{owner}/{repo}/issues{?state}",
+//		hypermedia.M{"owner": "lostisland", "repo": "go-sawyer", "state": "open"})
+func (c *Client) NewRequestTemplate(template string, params hypermedia.M) (*Request, error) {
+	u, err := hypermedia.Hyperlink(template).Expand(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewRequest(u.String())
+}
+
+// WithContext returns a shallow copy of the Request bound to the given context.
+func (r *Request) WithContext(ctx context.Context) *Request {
+	r2 := new(Request)
+	*r2 = *r
+	r2.Request = r.Request.WithContext(ctx)
+	return r2
+}
+
+// Clone returns a copy of the Request that can be changed and sent without
+// affecting the original, such as a template for several requests sent with
+// DoAll.  Its headers, query values, and URL are copied, and a body that can
+// be read again, like one set with SetBody or from a *bytes.Reader, is read
+// from the start through its own reader.  Other bodies, like one from a reader
+// that can only seek, are not copied, and the copy is sent without a body.  A
+// reader that is also an io.Closer, like an *os.File, is still closed once the
+// original is sent.
+func (r *Request) Clone() *Request {
+	r2 := new(Request)
+	*r2 = *r
+	r2.Request = r.Request.Clone(r.Context())
+	r2.Query = cloneValues(r.Query)
+	r2.urlQuery = cloneValues(r.urlQuery)
+	r2.defaultQuery = cloneValues(r.defaultQuery)
+	r2.expect = append([]int(nil), r.expect...)
+
+	// the original closes a shared reader once it is sent, and only it may
+	// seek one back for a retry
+	r2.bodyCloser = nil
+	r2.rewind = nil
+	r2.Body = nil
+	if r.GetBody != nil {
+		if body, err := r.GetBody(); err == nil {
+			r2.Body = body
+		}
+	}
+	if r2.Body == nil && r.Body != nil {
+		r2.Body = http.NoBody
+		r2.ContentLength = 0
+		r2.GetBody = nil
+	}
+	return r2
+}
+
+// ResolvedURL returns the URL the request will be sent to.  Query values are
+// merged in order of precedence: values set on the Request's Query, then
+// values from the URL given to NewRequest, then the Client's defaults.
+func (r *Request) ResolvedURL() (*url.URL, error) {
+	if r.URL == nil {
+		return nil, errors.New("No URL for this request")
+	}
+
+	u := *r.URL
+	u.RawQuery = r.Query.Encode()
+	return &u, nil
+}
+
+// ClearDefaultQuery removes the Client's default query values from this
+// Request.  Values from the URL given to NewRequest, and values set on Query
+// since, are kept.  A value set on Query that is the same as the default
+// cannot be told apart from it, and is removed too, so set it after calling
+// ClearDefaultQuery to keep it.
+func (r *Request) ClearDefaultQuery() {
+	for key, values := range r.defaultQuery {
+		if _, ok := r.urlQuery[key]; ok {
+			continue
+		}
+
+		if reflect.DeepEqual(r.Query[key], values) {
+			r.Query.Del(key)
+		}
+	}
+}
+
+// SetTimeout limits how long this Request may take, without changing the
+// Timeout of the shared *http.Client.  The deadline covers reading the body.
+func (r *Request) SetTimeout(d time.Duration) {
+	r.timeout = d
+}
+
+// ExpectStatus fails the Response with an error if its status is not one of
+// the given codes.  The body is kept in Response.RawBody.
+func (r *Request) ExpectStatus(codes ...int) {
+	r.expect = codes
+}
+
+// Do sends the request with the given method, and splits the Response's
+// errors from it.  A non-nil *Response is returned whenever the server
+// responded, even with an error status, and a non-nil error only if the
+// request failed before that.
+//
+//	res, err := client.Do(req, sawyer.GetMethod)
+//	if err != nil {
+//		return err // the server was not reached
+//	}
+//	if res.IsApiError() {
+//		...
+//	}
+func (c *Client) Do(req *Request, method string) (*Response, error) {
+	res := req.Do(method)
+	if res.Response == nil {
+		return nil, res.ResponseError
+	}
+	return res, nil
+}
+
+// Call sends the request with any method, such as the WebDAV PROPFIND, and
+// decodes a successful response into the output, unless it is nil.  As with
+// Client.Do, no Response is returned if the server was not reached.  An error
+// is also returned if the method is not a valid token, or if the response
+// fails to decode.
+//
+//	res, err := req.Call("PROPFIND", &props)
+func (r *Request) Call(method string, output interface{}) (*Response, error) {
+	if !validMethod(method) {
+		return nil, fmt.Errorf("Invalid method %q", method)
+	}
+
+	res := r.Do(method)
+	if res.Response == nil {
+		return nil, res.ResponseError
+	}
+
+	if output != nil && !res.isApiError {
+		if err := res.Decode(output); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// validMethod reports whether the method is a token, as defined by RFC 7230.
+func validMethod(method string) bool {
+	if len(method) == 0 {
+		return false
+	}
+
+	for _, c := range method {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
 }
 
+// Do sends the request with the given method.  It always returns a Response,
+// holding any error in its ResponseError field.  The Client's BeforeRequest
+// hooks run first, and its AfterResponse hooks run with every Response,
+// including errors.
 func (r *Request) Do(method string) *Response {
-	r.URL.RawQuery = r.Query.Encode()
+	start := time.Now()
+	res := r.do(method)
+	if r.client != nil {
+		r.client.logFinish(r, res, start)
+		for _, hook := range r.client.afterResponse {
+			hook(res)
+		}
+	}
+	return res
+}
+
+func (r *Request) do(method string) *Response {
 	r.Method = method
-	httpres, err := r.Client.Do(r.Request)
-	if err != nil {
-		return ResponseError(err)
+	if r.client != nil {
+		for _, hook := range r.client.beforeRequest {
+			if err := hook(r); err != nil {
+				return ResponseError(err)
+			}
+		}
 	}
 
-	mtype, err := mediaType(httpres)
-	if err != nil {
-		httpres.Body.Close()
-		return ResponseError(err)
+	r.URL.RawQuery = r.Query.Encode()
+	if r.client != nil {
+		r.client.logStart(r)
+	}
+
+	httpreq := r.Request
+	var cancel context.CancelFunc
+	if r.timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(r.Context(), r.timeout)
+		httpreq = r.Request.WithContext(ctx)
 	}
 
+	var timing *timingTrace
+	if r.client != nil && r.client.TraceTiming {
+		timing = newTimingTrace()
+		httpreq = httpreq.WithContext(httptrace.WithClientTrace(httpreq.Context(), timing.clientTrace()))
+	}
+
+	var cached *CachedResponse
+	var httpres *http.Response
+	fromCache := false
+	if r.cacheable() {
+		cached = r.client.Cacher.Get(httpreq)
+		if cached.Fresh(r.client.now()) {
+			httpres = cached.Response(httpreq)
+			fromCache = true
+		} else {
+			httpreq = revalidate(httpreq, cached)
+		}
+	}
+
+	if httpres == nil {
+		var err error
+		if httpres, fromCache, err = r.send(httpreq, cached); err != nil {
+			if cancel != nil {
+				cancel()
+			}
+			return ResponseError(err)
+		}
+	}
+
+	if cancel != nil {
+		httpres.Body = &cancelBody{httpres.Body, cancel}
+	}
+
+	// a malformed Content-Type is reported on the Response, which keeps its
+	// status, headers, and body
+	mtype, mtypeErr := mediaType(httpres)
+
 	headerDecoder := mediaheader.Decoder{}
 	mheader := headerDecoder.Decode(httpres.Header)
 
-	return &Response{nil, mtype, mheader, UseApiError(httpres.StatusCode), false, httpres}
+	res := &Response{
+		MediaType:   mtype,
+		MediaHeader: mheader,
+		FromCache:   fromCache,
+		isApiError:  UseApiError(httpres.StatusCode),
+		client:      r.client,
+		Response:    httpres,
+	}
+	if timing != nil {
+		res.Timing = timing.done()
+	}
+	if httpres.Request != nil {
+		res.FinalURL = httpres.Request.URL
+	}
+	if mtype == nil && mtypeErr == nil {
+		res.acceptType = acceptedType(r.Header)
+	}
+	res.Body = &countingBody{httpres.Body, &res.BytesRead}
+
+	res.closeEmptyBody(method)
+	if res.expectStatus(r.expect) && !r.stream {
+		res.bufferUndecodableError()
+	}
+	if mtypeErr != nil && res.ResponseError == nil {
+		res.ResponseError = mtypeErr
+	}
+	return res
+}
+
+// send performs the request, and caches the response if possible.  It reports
+// whether the cached response was replayed after a 304 Not Modified response.
+func (r *Request) send(httpreq *http.Request, cached *CachedResponse) (*http.Response, bool, error) {
+	if r.client != nil && r.client.ThrottleOnRateLimit {
+		if err := r.client.throttle(httpreq.Context()); err != nil {
+			return nil, false, r.contextError(httpreq, err)
+		}
+	}
+
+	if r.bodyCloser != nil {
+		defer r.bodyCloser.Close()
+	}
+
+	httpres, err := r.sendAuthorized(httpreq)
+	if err != nil {
+		return nil, false, r.contextError(httpreq, err)
+	}
+
+	if r.client != nil {
+		r.client.rate.update(httpres)
+	}
+
+	decompress(httpres)
+
+	if r.client != nil && r.client.MaxResponseBytes > 0 {
+		httpres.Body = &limitedBody{httpres.Body, r.client.MaxResponseBytes, r.client.MaxResponseBytes}
+	}
+
+	if r.cacheable() {
+		replayed := httpres.StatusCode == http.StatusNotModified && cached != nil &&
+			r.client.RevalidateTransparently
+		httpres, err = r.client.cache(httpreq, httpres, cached)
+		return httpres, replayed, err
+	}
+	return httpres, false, nil
+}
+
+// contextError prefers the context's error over the wrapped *url.Error that
+// the *http.Client returns when a request is cancelled.
+func (r *Request) contextError(httpreq *http.Request, err error) error {
+	ctxerr := httpreq.Context().Err()
+	if ctxerr == nil {
+		return err
+	}
+
+	if ctxerr == context.DeadlineExceeded && r.Context().Err() == nil {
+		return fmt.Errorf("Request timed out after %s", r.timeout)
+	}
+	return ctxerr
 }
 
 func (r *Request) Head() *Response {
@@ -77,26 +440,250 @@ func (r *Request) Options() *Response {
 	return r.Do(OptionsMethod)
 }
 
+// SetBasicAuth sets the Authorization header for this Request, overriding any
+// credentials from the Client.  Empty credentials are ignored.
+func (r *Request) SetBasicAuth(user, pass string) {
+	if len(user) > 0 || len(pass) > 0 {
+		r.Request.SetBasicAuth(user, pass)
+	}
+}
+
+// SetBearerToken sets an OAuth token in the Authorization header for this
+// Request, replacing any other credentials.  An empty token is ignored.
+func (r *Request) SetBearerToken(token string) {
+	if len(token) > 0 {
+		r.Header.Set(authHeader, "Bearer "+token)
+	}
+}
+
+// SetHeader sets a header for this Request, replacing any default from the
+// Client.  It returns the Request for chaining:
+//
+//	res := req.SetHeader("X-Foo", "bar").Get()
+func (r *Request) SetHeader(key, value string) *Request {
+	r.Header.Set(key, value)
+	return r
+}
+
+// AddHeader adds a value to a header for this Request, keeping any existing
+// values.  It returns the Request for chaining.
+func (r *Request) AddHeader(key, value string) *Request {
+	r.Header.Add(key, value)
+	return r
+}
+
+// SetAccept sets the Accept header for this Request.
+func (r *Request) SetAccept(mtype *mediatype.MediaType) {
+	r.SetAcceptTypes(mtype)
+}
+
+// SetAcceptTypes sets an Accept header listing several media types for this
+// Request.
+func (r *Request) SetAcceptTypes(mtypes ...*mediatype.MediaType) {
+	r.Header.Set(acceptHeader, acceptString(mtypes))
+}
+
+// SetBody encodes the input with the media type's encoder.  A []byte or an
+// io.Reader input is sent as is, as with SetRawBody and SetBodyReader.
 func (r *Request) SetBody(mtype *mediatype.MediaType, input interface{}) error {
 	r.MediaType = mtype
+	switch body := input.(type) {
+	case []byte:
+		r.SetRawBody(mtype.String(), body)
+		return nil
+	case io.Reader:
+		var length int64 = -1
+		if l, ok := body.(interface{ Len() int }); ok {
+			length = int64(l.Len())
+		}
+		r.SetBodyReader(mtype.String(), body, length)
+		return nil
+	}
+
 	buf, err := mtype.Encode(input)
 	if err != nil {
 		return err
 	}
 
-	r.Header.Set(ctypeHeader, mtype.String())
-	r.ContentLength = int64(buf.Len())
-	r.Body = ioutil.NopCloser(buf)
+	r.SetRawBody(mtype.String(), buf.Bytes())
 	return nil
 }
 
+// SetJSONBody encodes the input as an application/json body.
+func (r *Request) SetJSONBody(input interface{}) error {
+	return r.setBodyType(jsonType, input)
+}
+
+// SetXMLBody encodes the input as an application/xml body.
+func (r *Request) SetXMLBody(input interface{}) error {
+	return r.setBodyType(xmlType, input)
+}
+
+func (r *Request) setBodyType(ctype string, input interface{}) error {
+	mtype, err := mediatype.Parse(ctype)
+	if err != nil {
+		return err
+	}
+	return r.SetBody(mtype, input)
+}
+
+// SetRawBody sends the given bytes as the body, without encoding them.
+func (r *Request) SetRawBody(ctype string, body []byte) {
+	r.Header.Set(ctypeHeader, ctype)
+	r.ContentLength = int64(len(body))
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.buffered = true
+}
+
+// SetFormBody sends the given values as an application/x-www-form-urlencoded
+// body.
+func (r *Request) SetFormBody(values url.Values) {
+	body := values.Encode()
+	r.Header.Set(ctypeHeader, formType)
+	r.ContentLength = int64(len(body))
+	r.Body = ioutil.NopCloser(strings.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(body)), nil
+	}
+	r.buffered = true
+}
+
+// SetBodyReader streams the body from the given io.Reader without buffering
+// it.  Use a length of -1 if the size is unknown.  If the reader is also an
+// io.Closer, it is closed once the request is sent.  The request can only be
+// retried if the reader is also an io.Seeker.  A reader that is also an
+// io.ReaderAt, like a *bytes.Reader or an *os.File, is read through its own
+// io.SectionReader, so that GetBody returns an independent copy of it.
+func (r *Request) SetBodyReader(ctype string, body io.Reader, length int64) {
+	r.Header.Set(ctypeHeader, ctype)
+	r.ContentLength = length
+	r.GetBody = nil
+	r.bodyCloser = nil
+	r.rewind = nil
+	r.buffered = false
+
+	if seeker, ok := body.(io.Seeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			// the reader is closed after all attempts, not after the first
+			r.bodyCloser, _ = body.(io.Closer)
+
+			if section, ok := sectionReader(body, offset, length); ok {
+				r.Body = section()
+				r.GetBody = func() (io.ReadCloser, error) {
+					return section(), nil
+				}
+				return
+			}
+
+			r.Body = ioutil.NopCloser(body)
+			r.rewind = func() error {
+				_, err := seeker.Seek(offset, io.SeekStart)
+				return err
+			}
+			return
+		}
+	}
+
+	rc, ok := body.(io.ReadCloser)
+	if !ok {
+		rc = ioutil.NopCloser(body)
+	}
+	r.Body = rc
+}
+
+// sectionReader returns a function that builds independent readers of an
+// io.ReaderAt body, from the offset to the given length, or to its end if the
+// length is unknown.
+func sectionReader(body io.Reader, offset, length int64) (func() io.ReadCloser, bool) {
+	readerAt, ok := body.(io.ReaderAt)
+	if !ok {
+		return nil, false
+	}
+
+	if length < 0 {
+		seeker := body.(io.Seeker)
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, false
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, false
+		}
+		length = end - offset
+	}
+
+	return func() io.ReadCloser {
+		return ioutil.NopCloser(io.NewSectionReader(readerAt, offset, length))
+	}, true
+}
+
+// SetEmptyBody sends an empty body with an explicit "Content-Length: 0"
+// header, which some servers require for a POST or PUT without content.  Any
+// body and Content-Type set before are removed.
+func (r *Request) SetEmptyBody() {
+	r.Header.Del(ctypeHeader)
+	r.ContentLength = 0
+	r.Body = http.NoBody
+	r.GetBody = func() (io.ReadCloser, error) {
+		return http.NoBody, nil
+	}
+	r.bodyCloser = nil
+	r.buffered = false
+}
+
 const (
-	ctypeHeader   = "Content-Type"
-	HeadMethod    = "HEAD"
-	GetMethod     = "GET"
-	PostMethod    = "POST"
-	PutMethod     = "PUT"
-	PatchMethod   = "PATCH"
-	DeleteMethod  = "DELETE"
-	OptionsMethod = "OPTIONS"
+	ctypeHeader          = "Content-Type"
+	authHeader           = "Authorization"
+	acceptHeader         = "Accept"
+	acceptEncodingHeader = "Accept-Encoding"
+	cencHeader           = "Content-Encoding"
+	clenHeader           = "Content-Length"
+	formType             = "application/x-www-form-urlencoded"
+	jsonType             = "application/json"
+	xmlType              = "application/xml"
+	HeadMethod           = "HEAD"
+	GetMethod            = "GET"
+	PostMethod           = "POST"
+	PutMethod            = "PUT"
+	PatchMethod          = "PATCH"
+	DeleteMethod         = "DELETE"
+	OptionsMethod        = "OPTIONS"
 )
+
+// cancelBody releases a Request's timeout context once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// limitedBody fails reads once more than limit bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, fmt.Errorf("Response body exceeds the %d byte limit", b.limit)
+	}
+
+	b.remaining -= int64(n)
+	return n, err
+}
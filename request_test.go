@@ -1,13 +1,21 @@
 package sawyer
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/bmizerany/assert"
 	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestSuccessfulGet(t *testing.T) {
@@ -78,6 +86,26 @@ func TestSuccessfulGetWithoutOutput(t *testing.T) {
 	assert.Equal(t, "sawyer", user.Login)
 }
 
+func TestSuccessfulGetWithCharset(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json; charset=ISO-8859-1")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{\"id\": 1, \"login\": \"saw\xffer\"}"))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawÿer", user.Login)
+}
+
 func TestSuccessfulGetWithoutDecoder(t *testing.T) {
 	setup := Setup(t)
 	defer setup.Teardown()
@@ -99,6 +127,103 @@ func TestSuccessfulGetWithoutDecoder(t *testing.T) {
 	res := req.Get()
 	assert.NotEqual(t, nil, res.Decode(user), "response should have decoder error")
 	assert.Tf(t, strings.HasPrefix(res.Error(), "No decoder found for format booya"), "Bad error: %s", res.Error())
+	assert.Tf(t, strings.Contains(res.Error(), "(Content-Type: application/booya+booya); supported: "), "Bad error: %s", res.Error())
+	assert.Tf(t, strings.Contains(res.Error(), "json, "), "Bad error: %s", res.Error())
+}
+
+func TestSuccessfulGetSlice(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"id": 1, "login": "sawyer"}, {"id": 2, "login": "finn"}]`))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	users := []TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(&users))
+	assert.Equal(t, []TestUser{{1, "sawyer"}, {2, "finn"}}, users)
+}
+
+func TestSuccessfulGetMap(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"sawyer": {"id": 1, "login": "sawyer"}}`))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	users := map[string]TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(&users))
+	assert.Equal(t, map[string]TestUser{"sawyer": {1, "sawyer"}}, users)
+}
+
+func TestSuccessfulGetRawMessages(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/repo", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Link", `<https://api.github.com/repo/issues>; rel="issues"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name": "go-sawyer", "owner": {"id": 1, "login": "sawyer"}, "topics": ["http", "go"]}`))
+	})
+
+	req, err := setup.Client.NewRequest("repo")
+	assert.Equal(t, nil, err)
+
+	fields := map[string]json.RawMessage{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(&fields))
+	assert.Equal(t, 3, len(fields))
+	assert.Equal(t, `"go-sawyer"`, string(fields["name"]))
+
+	owner := &TestUser{}
+	assert.Equal(t, nil, json.Unmarshal(fields["owner"], owner))
+	assert.Equal(t, "sawyer", owner.Login)
+
+	var topics []string
+	assert.Equal(t, nil, json.Unmarshal(fields["topics"], &topics))
+	assert.Equal(t, []string{"http", "go"}, topics)
+
+	issues, err := res.Rel("issues")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/repo/issues", issues.URL.Path)
+}
+
+func TestSuccessfulGetSliceWithoutDecoder(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/booya+booya")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"id": 1, "login": "sawyer"}]`))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	users := []TestUser{}
+	res := req.Get()
+	assert.NotEqual(t, nil, res.Decode(&users), "response should have decoder error")
+	assert.Tf(t, strings.HasPrefix(res.Error(), "No decoder found for format booya"), "Bad error: %s", res.Error())
+	assert.Equal(t, 0, len(users))
 }
 
 func TestSuccessfulPost(t *testing.T) {
@@ -140,6 +265,94 @@ func TestSuccessfulPost(t *testing.T) {
 	assert.Equal(t, true, res.BodyClosed)
 }
 
+func TestPostWithCharset(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json; charset=utf-8", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	mtype, err := mediatype.Parse("application/json")
+	assert.Equal(t, nil, err)
+	mtype.SetParam("charset", "utf-8")
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetBody(mtype, &TestUser{1, "sawyer"}))
+	assert.Equal(t, 201, req.Post().StatusCode)
+}
+
+func TestSuccessfulXmlPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	mtype, err := mediatype.Parse("application/xml")
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/xml", r.Header.Get("Content-Type"))
+
+		user := &TestXmlUser{}
+		mtype.Decode(user, r.Body)
+		assert.Equal(t, "sawyer", user.Login)
+
+		head := w.Header()
+		head.Set("Content-Type", "application/atom+xml")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`<user><id>2</id><login>sawyer2</login></user>`))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	user := &TestXmlUser{Login: "sawyer"}
+	assert.Equal(t, nil, req.SetBody(mtype, user))
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, "xml", res.MediaType.Format)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, 2, user.Id)
+	assert.Equal(t, "sawyer2", user.Login)
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestJSONAndXMLBodies(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.Header.Get("Content-Type") {
+		case "application/json":
+			assert.Equal(t, `{"id":1,"login":"sawyer"}`+"\n", string(body))
+		case "application/xml":
+			assert.Equal(t, `<user><id>1</id><login>sawyer</login></user>`, string(body))
+		default:
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+	assert.Equal(t, "json", req.MediaType.Format)
+	assert.Equal(t, 201, req.Post().StatusCode)
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetXMLBody(&TestXmlUser{Id: 1, Login: "sawyer"}))
+	assert.Equal(t, 201, req.Post().StatusCode)
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, nil, req.SetJSONBody(make(chan int)))
+}
+
 func TestErrorResponse(t *testing.T) {
 	setup := Setup(t)
 	defer setup.Teardown()
@@ -172,6 +385,126 @@ func TestErrorResponse(t *testing.T) {
 	assert.Equal(t, true, res.BodyClosed)
 }
 
+func TestResolvedURL(t *testing.T) {
+	client, err := NewFromString("https://api.github.com/api?a=1&b=1&c=1", nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("users?b=2&c=2")
+	assert.Equal(t, nil, err)
+	req.Query.Set("c", "3")
+	req.Query.Set("d", "3")
+	before := req.URL.String()
+
+	u, err := req.ResolvedURL()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https://api.github.com/api/users?a=1&b=2&c=3&d=3", u.String())
+	assert.Equal(t, before, req.URL.String())
+}
+
+func TestResolvedURLMatchesRequest(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	var sent string
+	setup.Mux.HandleFunc("/q", func(w http.ResponseWriter, r *http.Request) {
+		sent = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.Query.Set("c", "3")
+	req, err := setup.Client.NewRequest("/q?b=2&d=4")
+	assert.Equal(t, nil, err)
+	req.Query.Set("d", "2")
+
+	u, err := req.ResolvedURL()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "a=1&b=2&c=3&d=2", u.RawQuery)
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, u.RawQuery, sent)
+}
+
+func TestNewRequestResolvesURLs(t *testing.T) {
+	client, err := NewFromString("https://api.github.com/api?a=1", nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("users/sawyer")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https://api.github.com/api/users/sawyer?a=1", req.URL.String())
+
+	req, err = client.NewRequest("/users/sawyer")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https://api.github.com/users/sawyer?a=1", req.URL.String())
+
+	req, err = client.NewRequest("http://cdn.example.com/avatars/sawyer.png?s=40")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "http", req.URL.Scheme)
+	assert.Equal(t, "cdn.example.com", req.URL.Host)
+	assert.Equal(t, "/avatars/sawyer.png", req.URL.Path)
+	assert.Equal(t, "a=1&s=40", req.URL.RawQuery)
+}
+
+func TestNewRequestPath(t *testing.T) {
+	client, err := NewFromString("https://api.github.com/api?a=1", nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequestPath("repos", "lostisland", "go-sawyer", "issues")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https://api.github.com/api/repos/lostisland/go-sawyer/issues?a=1", req.URL.String())
+
+	req, err = client.NewRequestPath("files", "a/b c", "", "x?y#z")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https://api.github.com/api/files/a%2Fb%20c/x%3Fy%23z?a=1", req.URL.String())
+	assert.Equal(t, "/api/files/a/b c/x?y#z", req.URL.Path)
+
+	req, err = client.NewRequestPath("user:1", "..")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https://api.github.com/api/user:1/%2E%2E?a=1", req.URL.String())
+}
+
+func TestClearDefaultQuery(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/q", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		_, ok := q["a"]
+		assert.Equal(t, false, ok)
+		assert.Equal(t, "2", q.Get("b"))
+		assert.Equal(t, "3", q.Get("c"))
+		assert.Equal(t, "4", q.Get("d"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.Query.Set("c", "1")
+	setup.Client.Query.Set("d", "1")
+	req, err := setup.Client.NewRequest("/q?b=2")
+	assert.Equal(t, nil, err)
+
+	req.Query.Set("c", "3")
+	req.Query.Set("d", "4")
+	req.ClearDefaultQuery()
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, "1", setup.Client.Query.Get("a"))
+}
+
+func TestClearDefaultQuerySameValue(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	req, err := setup.Client.NewRequest("/q")
+	assert.Equal(t, nil, err)
+
+	req.Query.Set("a", "1")
+	req.ClearDefaultQuery()
+	_, ok := req.Query["a"]
+	assert.Equal(t, false, ok)
+
+	req.Query.Set("a", "1")
+	u, err := req.ResolvedURL()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "a=1", u.RawQuery)
+}
+
 func TestResolveRequestQuery(t *testing.T) {
 	setup := Setup(t)
 	defer setup.Teardown()
@@ -206,9 +539,594 @@ func TestResolveRequestQuery(t *testing.T) {
 	assert.Equal(t, 123, res.StatusCode)
 }
 
-type TestUser struct {
-	Id    int    `json:"id"`
-	Login string `json:"login"`
+func TestHead(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "HEAD", r.Method)
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Content-Length", "28")
+		head.Set("X-Sawyer", "head")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Head()
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, int64(28), res.ContentLength)
+	assert.Equal(t, "head", res.Header.Get("X-Sawyer"))
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, TestUser{}, *user)
+}
+
+func TestNotModifiedWithoutBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusNotModified)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, 304, res.StatusCode)
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, nil, res.Decode(&TestUser{}))
+}
+
+func TestNoContentDelete(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{1, "sawyer"}
+	res := req.Delete()
+	assert.Equal(t, 204, res.StatusCode)
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, TestUser{1, "sawyer"}, *user)
+}
+
+func TestEmptyBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, TestUser{}, *user)
+}
+
+func TestEmptyChunkedBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, int64(-1), res.ContentLength)
+	assert.Equal(t, false, res.BodyClosed)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, TestUser{}, *user)
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	setup.Client.MaxResponseBytes = 10
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.NotEqual(t, nil, res.Decode(&TestUser{}))
+	assert.Equal(t, "Response body exceeds the 10 byte limit", res.Error())
+
+	setup.Client.MaxResponseBytes = 28
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res = req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+}
+
+func TestCancelledRequest(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := setup.Client.NewRequestWithContext(ctx, "slow")
+	assert.Equal(t, nil, err)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	res := req.Get()
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, context.Canceled.Error(), res.Error())
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestRequestWithContext(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctxreq := req.WithContext(ctx)
+	assert.Equal(t, ctx, ctxreq.Context())
+	assert.Equal(t, context.Background(), req.Context())
+	assert.Equal(t, req.URL.String(), ctxreq.URL.String())
+}
+
+func TestRequestTimeout(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	slowreq, err := setup.Client.NewRequest("slow")
+	assert.Equal(t, nil, err)
+	slowreq.SetTimeout(10 * time.Millisecond)
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	req.SetTimeout(time.Second)
+
+	slowch := make(chan *Response)
+	go func() { slowch <- slowreq.Get() }()
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+
+	res = <-slowch
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, "Request timed out after 10ms", res.Error())
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestFormPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		assert.Equal(t, int64(28), r.ContentLength)
+
+		assert.Equal(t, nil, r.ParseForm())
+		assert.Equal(t, "sawyer", r.PostForm.Get("login"))
+		assert.Equal(t, []string{"a", "b"}, r.PostForm["scope"])
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("session")
+	assert.Equal(t, nil, err)
+
+	values := url.Values{}
+	values.Set("login", "sawyer")
+	values.Add("scope", "a")
+	values.Add("scope", "b")
+	req.SetFormBody(values)
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 201, res.StatusCode)
+}
+
+func TestStreamingPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+		assert.Equal(t, []string{"chunked"}, r.TransferEncoding)
+
+		body, err := ioutil.ReadAll(r.Body)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "streamed body", string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("upload")
+	assert.Equal(t, nil, err)
+
+	body := &closingReader{strings.NewReader("streamed body"), make(chan bool, 1)}
+	req.SetBodyReader("text/plain", body, -1)
+
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 201, res.StatusCode)
+
+	select {
+	case <-body.closed:
+	case <-time.After(time.Second):
+		t.Fatal("request body was not closed")
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	setup.Mux.HandleFunc("/404", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "not found"}`))
+	})
+
+	setup.Mux.HandleFunc("/500", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<h1>Oops</h1>"))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res, err := setup.Client.Do(req, GetMethod)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+
+	req, err = setup.Client.NewRequest("404")
+	assert.Equal(t, nil, err)
+
+	apierr := &TestError{}
+	res, err = setup.Client.Do(req, GetMethod)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 404, res.StatusCode)
+	assert.Equal(t, true, res.IsApiError())
+	assert.Equal(t, nil, res.Decode(apierr))
+	assert.Equal(t, "not found", apierr.Message)
+
+	req, err = setup.Client.NewRequest("500")
+	assert.Equal(t, nil, err)
+
+	res, err = setup.Client.Do(req, GetMethod)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 500, res.StatusCode)
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, "<h1>Oops</h1>", string(res.RawBody))
+}
+
+func TestClientDoMalformedContentType(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/500", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "text/")
+		head.Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Oops"))
+	})
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/")
+		w.Write([]byte("sawyer"))
+	})
+
+	req, err := setup.Client.NewRequest("500")
+	assert.Equal(t, nil, err)
+
+	res, err := setup.Client.Do(req, GetMethod)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 500, res.StatusCode)
+	assert.Equal(t, "abc", res.Header.Get("X-Request-Id"))
+	assert.Equal(t, (*mediatype.MediaType)(nil), res.MediaType)
+	assert.Equal(t, true, res.IsDecodeError())
+	assert.Equal(t, "Oops", string(res.RawBody))
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res, err = setup.Client.Do(req, GetMethod)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "mime: expected token after slash", res.Error())
+	assert.Equal(t, res.ResponseError, res.Decode(&TestUser{}))
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "sawyer", string(body))
+}
+
+func TestCall(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PROPFIND", r.Method)
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(207)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res, err := req.Call("PROPFIND", user)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 207, res.StatusCode)
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, true, res.BodyClosed)
+
+	for _, method := range []string{"", "GET /", "PROP\nFIND", "(GET)"} {
+		res, err = req.Call(method, nil)
+		assert.Equal(t, (*Response)(nil), res)
+		assert.Equal(t, fmt.Sprintf("Invalid method %q", method), err.Error())
+	}
+}
+
+func TestClientDoFailure(t *testing.T) {
+	setup := Setup(t)
+	setup.Teardown()
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res, err := setup.Client.Do(req, GetMethod)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, (*Response)(nil), res)
+
+	hookErr := errors.New("not allowed")
+	setup.Client.BeforeRequest(func(req *Request) error {
+		return hookErr
+	})
+
+	res, err = setup.Client.Do(req, GetMethod)
+	assert.Equal(t, hookErr, err)
+	assert.Equal(t, (*Response)(nil), res)
+}
+
+func TestRawBodyPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, int64(28), r.ContentLength)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"id": 1, "login": "sawyer"}`, string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	req.SetRawBody("application/json", []byte(`{"id": 1, "login": "sawyer"}`))
+	assert.Equal(t, 201, req.Post().StatusCode)
+
+	mtype, _ := mediatype.Parse("application/json")
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetBody(mtype, []byte(`{"id": 1, "login": "sawyer"}`)))
+	assert.Equal(t, 201, req.Post().StatusCode)
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetBody(mtype, strings.NewReader(`{"id": 1, "login": "sawyer"}`)))
+	assert.Equal(t, 201, req.Post().StatusCode)
+}
+
+func TestEmptyBodyPost(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users/1/follow", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "0", r.Header.Get("Content-Length"))
+		assert.Equal(t, "", r.Header.Get("Content-Type"))
+		assert.Equal(t, []string(nil), r.TransferEncoding)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, method := range []string{PostMethod, PutMethod} {
+		req, err := setup.Client.NewRequest("users/1/follow")
+		assert.Equal(t, nil, err)
+
+		req.SetRawBody("text/plain", []byte("discarded"))
+		req.SetEmptyBody()
+		assert.Equal(t, 204, req.Do(method).StatusCode)
+	}
+}
+
+func TestRequestClone(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		head := w.Header()
+		head.Set("X-Body", string(body))
+		head.Set("X-Trace", r.Header.Get("X-Trace"))
+		head.Set("X-Page", r.URL.Query().Get("page"))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	req.Header.Set("X-Trace", "original")
+	req.Query.Set("page", "1")
+	req.SetRawBody("application/json", []byte(`{"login": "sawyer"}`))
+
+	clone := req.Clone()
+	clone.Header.Set("X-Trace", "clone")
+	clone.Query.Set("page", "2")
+
+	assert.Equal(t, "original", req.Header.Get("X-Trace"))
+	assert.Equal(t, "1", req.Query.Get("page"))
+
+	responses := setup.Client.DoAll([]*Request{req.Clone(), clone, req}, nil, 3)
+	for i, trace := range []string{"original", "clone", "original"} {
+		res := responses[i]
+		assert.Equal(t, 201, res.StatusCode)
+		assert.Equal(t, `{"login": "sawyer"}`, res.Header.Get("X-Body"))
+		assert.Equal(t, trace, res.Header.Get("X-Trace"))
+	}
+	assert.Equal(t, "2", responses[1].Header.Get("X-Page"))
+	assert.Equal(t, "1", responses[2].Header.Get("X-Page"))
+}
+
+func TestSeekableBodyGetBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "sawyer", string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	mtype, err := mediatype.Parse("text/plain")
+	assert.Equal(t, nil, err)
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	reader := bytes.NewReader([]byte("--sawyer"))
+	reader.Seek(2, io.SeekStart)
+	assert.Equal(t, nil, req.SetBody(mtype, reader))
+
+	body, err := req.GetBody()
+	assert.Equal(t, nil, err)
+	copied, _ := ioutil.ReadAll(body)
+	assert.Equal(t, "sawyer", string(copied))
+
+	assert.Equal(t, 201, req.Post().StatusCode)
+}
+
+func TestCloneSeekableBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Body", string(body))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	req.Method = PostMethod
+	reader := bytes.NewReader([]byte(`{"login": "sawyer"}`))
+	req.SetBodyReader("application/json", reader, reader.Size())
+
+	responses := setup.Client.DoAll([]*Request{req.Clone(), req.Clone(), req}, nil, 3)
+	for _, res := range responses {
+		assert.Equal(t, 201, res.StatusCode)
+		assert.Equal(t, `{"login": "sawyer"}`, res.Header.Get("X-Body"))
+	}
+	assert.Equal(t, reader.Size(), int64(reader.Len()))
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	req.Method = PostMethod
+	req.SetBodyReader("text/plain", &seekOnlyReader{strings.NewReader("sawyer")}, 6)
+
+	clone := req.Clone()
+	assert.Equal(t, http.NoBody, clone.Body)
+	assert.Equal(t, "", clone.Do(PostMethod).Header.Get("X-Body"))
+	assert.Equal(t, "sawyer", req.Do(PostMethod).Header.Get("X-Body"))
+}
+
+type closingReader struct {
+	*strings.Reader
+	closed chan bool
+}
+
+func (r *closingReader) Close() error {
+	select {
+	case r.closed <- true:
+	default:
+	}
+	return nil
+}
+
+type TestUser struct {
+	Id    int    `json:"id"`
+	Login string `json:"login"`
+}
+
+type TestXmlUser struct {
+	XMLName struct{} `xml:"user"`
+	Id      int      `xml:"id"`
+	Login   string   `xml:"login"`
 }
 
 type TestError struct {
@@ -1,25 +1,75 @@
 package sawyer
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"github.com/lostisland/go-sawyer/hypermedia"
 	"github.com/lostisland/go-sawyer/mediaheader"
 	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 type Response struct {
 	ResponseError error
-	MediaType     *mediatype.MediaType
-	MediaHeader   *mediaheader.MediaHeader
-	isApiError    bool
-	BodyClosed    bool
+
+	// MediaType is parsed from the Content-Type header, with its parameters,
+	// like charset.  It is nil if the response has no Content-Type.
+	MediaType *mediatype.MediaType
+
+	MediaHeader *mediaheader.MediaHeader
+	isApiError  bool
+	BodyClosed  bool
+
+	// RawBody holds the body of a response that could not be decoded, if the
+	// Client's BufferResponseOnError option is set.  It always holds the body
+	// of an error response with a media type that has no decoder.
+	RawBody []byte
+
+	// Timing holds how long the request took, if the Client's TraceTiming
+	// option is set.
+	Timing Timing
+
+	// FinalURL is the URL that served the response, after the query is
+	// merged and any redirects are followed.
+	FinalURL *url.URL
+
+	// FromCache is set if the response was replayed from the Client's Cacher,
+	// either because it was fresh, or after a 304 Not Modified response.
+	FromCache bool
+
+	// Restarted is set by Request.DownloadTo if the server sent the whole
+	// resource instead of the requested range.
+	Restarted bool
+
+	// BytesRead counts the bytes read from the body so far, after any
+	// decompression.  It is complete once the body is decoded or drained, and
+	// grows as the caller reads the body of a streamed response.
+	BytesRead int64
+
+	client     *Client
+	apiError   *ApiError
+	acceptType *mediatype.MediaType
+	bodyRels   hypermedia.Relations
+	embedded   hypermedia.Embedded
+	links      map[string]string
 	*http.Response
 }
 
+// AnyError reports whether anything went wrong: a transport or decoding error
+// from IsError, or an error status from IsApiError.
 func (r *Response) AnyError() bool {
 	return r.IsError() || r.IsApiError()
 }
 
+// IsError reports whether the request failed, or the body failed to be read
+// or decoded, as either IsTransportError or IsDecodeError does.  Error
+// statuses are reported by IsApiError, IsClientError, and IsServerError
+// instead.
 func (r *Response) IsError() bool {
 	return r.ResponseError != nil
 }
@@ -28,6 +78,28 @@ func (r *Response) IsApiError() bool {
 	return r.isApiError
 }
 
+// IsTransportError reports whether the request failed before any response was
+// received, such as when the connection was refused.
+func (r *Response) IsTransportError() bool {
+	return r.Response == nil && r.ResponseError != nil
+}
+
+// IsDecodeError reports whether a response was received, but reading or
+// decoding its body failed afterwards.
+func (r *Response) IsDecodeError() bool {
+	return r.Response != nil && r.ResponseError != nil
+}
+
+// IsClientError reports whether the response has a 4xx status.
+func (r *Response) IsClientError() bool {
+	return r.Response != nil && r.StatusCode >= 400 && r.StatusCode < 500
+}
+
+// IsServerError reports whether the response has a 5xx status.
+func (r *Response) IsServerError() bool {
+	return r.Response != nil && r.StatusCode >= 500 && r.StatusCode < 600
+}
+
 func (r *Response) Error() string {
 	if r.ResponseError != nil {
 		return r.ResponseError.Error()
@@ -35,27 +107,381 @@ func (r *Response) Error() string {
 	return ""
 }
 
-func (r *Response) Decode(resource interface{}) error {
-	if r.MediaType == nil {
-		return errors.New("No media type for this response")
+// Err returns an *ApiError for responses with an error status, or the
+// ResponseError if the request failed.  It returns nil for successful
+// responses.
+//
+//	var apierr *sawyer.ApiError
+//	if errors.As(res.Err(), &apierr) && apierr.StatusCode == 404 {
+//		...
+//	}
+func (r *Response) Err() error {
+	if !r.isApiError {
+		if r.ResponseError != nil {
+			return r.ResponseError
+		}
+		return nil
+	}
+
+	if r.apiError == nil {
+		r.apiError = &ApiError{StatusCode: r.StatusCode, Body: r.RawBody}
+		if !r.BodyClosed {
+			raw := newRawBuffer(r.rawBodyLimit())
+			raw.ReadFrom(r.Body)
+			r.Body.Close()
+			r.BodyClosed = true
+			r.apiError.Body = raw.Bytes()
+		}
 	}
+	return r.apiError
+}
 
+func (r *Response) Decode(resource interface{}) error {
 	if resource == nil || r.ResponseError != nil || r.BodyClosed {
 		return r.ResponseError
 	}
 
+	if r.decodeType() == nil && r.fallbackType() == nil {
+		return errors.New("No media type for this response")
+	}
+
 	defer r.Body.Close()
 	r.BodyClosed = true
 
-	dec, err := r.MediaType.Decoder(r.Body)
+	var src io.Reader = r.Body
+	if r.client != nil && r.client.TeeResponseBody {
+		tee := new(bytes.Buffer)
+		src = io.TeeReader(r.Body, tee)
+		defer func() {
+			io.Copy(ioutil.Discard, src)
+			r.Body = ioutil.NopCloser(bytes.NewReader(tee.Bytes()))
+		}()
+	}
+
+	body := src
+	var raw *rawBuffer
+	if r.isApiError || r.bufferOnError() {
+		raw = newRawBuffer(r.rawBodyLimit())
+		body = io.TeeReader(src, raw)
+	}
+
+	counter := &countingReader{Reader: body}
+	dec, err := r.decoder(counter)
 	if err != nil {
 		r.ResponseError = err
 	} else {
 		r.ResponseError = dec.Decode(resource)
 	}
+
+	// an empty body of unknown length leaves the resource untouched
+	if r.ResponseError == io.EOF && counter.n == 0 {
+		r.ResponseError = nil
+		return nil
+	}
+
+	if r.ResponseError == nil {
+		r.decodeHypermedia(dec, resource)
+		if r.client != nil && r.client.decodeHook != nil && !r.isApiError {
+			r.ResponseError = r.client.decodeHook(r, resource)
+		}
+	} else if r.bufferOnError() {
+		raw.ReadFrom(src)
+		r.RawBody = raw.Bytes()
+	}
+
+	if r.isApiError {
+		raw.ReadFrom(src)
+		r.apiError = &ApiError{StatusCode: r.StatusCode, Body: raw.Bytes()}
+		if r.ResponseError == nil {
+			r.apiError.Resource = resource
+		}
+	}
 	return r.ResponseError
 }
 
+// closeEmptyBody closes the body of responses that have none, so that they
+// are not decoded.
+func (r *Response) closeEmptyBody(method string) {
+	switch {
+	case method == HeadMethod:
+	case r.StatusCode == http.StatusNoContent:
+	case r.StatusCode == http.StatusNotModified:
+	case r.ContentLength == 0:
+	default:
+		return
+	}
+
+	r.Body.Close()
+	r.BodyClosed = true
+}
+
+// expectStatus reports whether the response has one of the given statuses,
+// if any are given.  Otherwise, the body is read into RawBody, and the
+// response fails.
+func (r *Response) expectStatus(codes []int) bool {
+	if len(codes) == 0 {
+		return true
+	}
+
+	for _, code := range codes {
+		if r.StatusCode == code {
+			return true
+		}
+	}
+
+	if !r.BodyClosed {
+		raw := newRawBuffer(r.rawBodyLimit())
+		raw.ReadFrom(r.Body)
+		r.Body.Close()
+		r.BodyClosed = true
+		r.RawBody = raw.Bytes()
+	}
+
+	r.ResponseError = fmt.Errorf("Unexpected status %d, expected %v", r.StatusCode, codes)
+	return false
+}
+
+// bufferUndecodableError reads the body of an error response into RawBody if
+// its media type has no decoder, such as an HTML error page.
+func (r *Response) bufferUndecodableError() {
+	if r.StatusCode < 400 || r.ContentLength == 0 {
+		return
+	}
+
+	if r.decodeType() != nil || r.fallbackType() != nil {
+		if _, err := r.decoder(bytes.NewReader(nil)); err == nil {
+			return
+		}
+	}
+
+	raw := newRawBuffer(r.rawBodyLimit())
+	_, err := raw.ReadFrom(r.Body)
+	r.Body.Close()
+	r.BodyClosed = true
+	r.RawBody = raw.Bytes()
+
+	if err != nil && err != io.EOF {
+		r.ResponseError = err
+	} else if len(r.RawBody) > 0 {
+		r.ResponseError = fmt.Errorf("Unable to decode %s response of type %q",
+			r.Status, r.Header.Get(ctypeHeader))
+	}
+}
+
+// decoder returns a decoder for the body from the response's media type, or
+// from the Client's fallback media type if the response's has no decoder.
+func (r *Response) decoder(body io.Reader) (mediatype.Decoder, error) {
+	mtype, fallback := r.decodeType(), r.fallbackType()
+	if mtype == nil {
+		mtype = fallback
+	}
+
+	dec, err := mtype.Decoder(body)
+	if err != nil && fallback != nil {
+		dec, err = fallback.Decoder(body)
+	}
+
+	if err == nil && r.client != nil {
+		r.client.configureDecoder(dec)
+	}
+	return dec, err
+}
+
+// configureDecoder applies the Client's JSON decoding options to decoders that
+// support them, like *json.Decoder.
+func (c *Client) configureDecoder(dec mediatype.Decoder) {
+	if jsondec, ok := dec.(interface{ UseNumber() }); ok && c.UseJSONNumber {
+		jsondec.UseNumber()
+	}
+	if jsondec, ok := dec.(interface{ DisallowUnknownFields() }); ok && c.DisallowUnknownFields {
+		jsondec.DisallowUnknownFields()
+	}
+}
+
+// decodeType returns the response's media type, or else the one media type
+// that the request accepted.
+func (r *Response) decodeType() *mediatype.MediaType {
+	if r.MediaType != nil {
+		return r.MediaType
+	}
+	return r.acceptType
+}
+
+func (r *Response) fallbackType() *mediatype.MediaType {
+	if r.client != nil {
+		return r.client.fallback
+	}
+	return nil
+}
+
+func (r *Response) bufferOnError() bool {
+	return r.client != nil && r.client.BufferResponseOnError
+}
+
+func (r *Response) rawBodyLimit() int64 {
+	if r.client != nil {
+		return r.client.RawBodyLimit
+	}
+	return 0
+}
+
+// NotModified reports whether the server responded with 304 Not Modified to a
+// conditional request.  The response has no body to decode.
+func (r *Response) NotModified() bool {
+	return r.Response != nil && r.StatusCode == http.StatusNotModified
+}
+
+// HeaderValue returns the first value of the given response header, or "" if
+// it is missing.  It is named so as not to hide the embedded Header field, and
+// is safe to call on a Response that failed before the server responded.
+func (r *Response) HeaderValue(key string) string {
+	if r.Response == nil {
+		return ""
+	}
+	return r.Header.Get(key)
+}
+
+// HeaderValues returns all the values of the given response header.
+func (r *Response) HeaderValues(key string) []string {
+	if r.Response == nil {
+		return nil
+	}
+	return r.Header.Values(key)
+}
+
+// HasHeader reports whether the response has the given header, even if its
+// value is empty.
+func (r *Response) HasHeader(key string) bool {
+	if r.Response == nil {
+		return false
+	}
+	_, ok := r.Header[http.CanonicalHeaderKey(key)]
+	return ok
+}
+
+// AllowedMethods returns the uppercased methods listed in the Allow header,
+// such as from an OPTIONS request.
+func (r *Response) AllowedMethods() []string {
+	if r.Response == nil {
+		return nil
+	}
+
+	var methods []string
+	for _, value := range r.Header.Values(allowHeader) {
+		for _, method := range strings.Split(value, ",") {
+			if method = strings.TrimSpace(method); len(method) > 0 {
+				methods = append(methods, strings.ToUpper(method))
+			}
+		}
+	}
+	return methods
+}
+
+// Embedded decodes an embedded resource from a decoded HAL response into v.
+func (r *Response) Embedded(name string, v interface{}) error {
+	return r.embedded.Decode(name, v)
+}
+
+func (r *Response) decodeHypermedia(dec mediatype.Decoder, resource interface{}) {
+	r.bodyRels = make(hypermedia.Relations)
+	if hal, ok := dec.(*hypermedia.HALDecoder); ok {
+		r.embedded = hal.Resource.Embedded
+		for name, link := range hal.Rels() {
+			r.bodyRels[name] = link
+		}
+	}
+
+	if hr, ok := resource.(hypermedia.HypermediaResource); ok {
+		for name, link := range hr.Rels() {
+			r.bodyRels[name] = link
+		}
+	}
+}
+
+// Rel builds a Request for the named link relation.  Relations are looked up
+// in the sources enabled by the Client's Relations field.  Relations from the
+// body are only available after the response is decoded into a
+// hypermedia.HypermediaResource.
+//
+//	res := req.Get()
+//	res.Decode(user)
+//	req, err = res.Rel("repos")
+func (r *Response) Rel(name string) (*Request, error) {
+	if r.client == nil {
+		return nil, errors.New("No client for this response")
+	}
+
+	u, err := r.relations().Rel(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.NewRequest(u.String())
+}
+
+// Links returns the relations from the Link header as a map of rel names to
+// hrefs.  A link with several rel values is listed under each of them.
+func (r *Response) Links() map[string]string {
+	if r.links == nil {
+		r.links = make(map[string]string)
+		if r.MediaHeader != nil {
+			for name, link := range r.MediaHeader.Relations {
+				r.links[name] = string(link)
+			}
+		}
+	}
+	return r.links
+}
+
+// NextPage builds a Request for the "next" Link relation.  It returns false if
+// there is no next page.
+func (r *Response) NextPage() (*Request, bool) {
+	return r.page("next")
+}
+
+// PrevPage builds a Request for the "prev" Link relation.
+func (r *Response) PrevPage() (*Request, bool) {
+	return r.page("prev")
+}
+
+// FirstPage builds a Request for the "first" Link relation.
+func (r *Response) FirstPage() (*Request, bool) {
+	return r.page("first")
+}
+
+// LastPage builds a Request for the "last" Link relation.
+func (r *Response) LastPage() (*Request, bool) {
+	return r.page("last")
+}
+
+func (r *Response) page(rel string) (*Request, bool) {
+	href, ok := r.Links()[rel]
+	if !ok || r.client == nil {
+		return nil, false
+	}
+
+	req, err := r.client.NewRequest(href)
+	if err != nil {
+		return nil, false
+	}
+	return req, true
+}
+
+func (r *Response) relations() hypermedia.Relations {
+	rels := make(hypermedia.Relations)
+	if r.client.Relations&LinkHeaderRelations != 0 && r.MediaHeader != nil {
+		for name, link := range r.MediaHeader.Relations {
+			rels[name] = link
+		}
+	}
+
+	if r.client.Relations&BodyRelations != 0 {
+		for name, link := range r.bodyRels {
+			rels[name] = link
+		}
+	}
+	return rels
+}
+
 func (r *Response) decode(output interface{}) {
 	if !r.isApiError {
 		r.Decode(output)
@@ -66,6 +492,19 @@ func ResponseError(err error) *Response {
 	return &Response{ResponseError: err, BodyClosed: true}
 }
 
+// ApiError is the error for a response with an error status.  Body holds the
+// raw response body, up to the Client's RawBodyLimit, and Resource holds the
+// value it was decoded into, if any.
+type ApiError struct {
+	StatusCode int
+	Body       []byte
+	Resource   interface{}
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
 func UseApiError(status int) bool {
 	switch {
 	case status > 199 && status < 300:
@@ -84,3 +523,80 @@ func mediaType(res *http.Response) (*mediatype.MediaType, error) {
 	}
 	return nil, nil
 }
+
+// acceptedType returns the media type of an Accept header that lists exactly
+// one, without wildcards.
+func acceptedType(header http.Header) *mediatype.MediaType {
+	values := header.Values(acceptHeader)
+	if len(values) != 1 || strings.ContainsAny(values[0], ",*") {
+		return nil
+	}
+
+	mtype, err := mediatype.Parse(values[0])
+	if err != nil {
+		return nil
+	}
+	return mtype
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingBody adds the bytes read from a response body to n.
+type countingBody struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.n += int64(n)
+	return n, err
+}
+
+// rawBuffer keeps up to limit bytes written to it, and drops the rest.
+type rawBuffer struct {
+	bytes.Buffer
+	limit int64
+}
+
+func newRawBuffer(limit int64) *rawBuffer {
+	if limit <= 0 {
+		limit = DefaultRawBodyLimit
+	}
+	return &rawBuffer{limit: limit}
+}
+
+func (b *rawBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - int64(b.Len()); room < int64(len(p)) {
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// ReadFrom reads the rest of r until the buffer is full.
+func (b *rawBuffer) ReadFrom(r io.Reader) (int64, error) {
+	room := b.limit - int64(b.Len())
+	if room <= 0 {
+		return 0, nil
+	}
+	return io.CopyN(&b.Buffer, r, room)
+}
+
+const allowHeader = "Allow"
+
+// DefaultRawBodyLimit is the most bytes of an undecodable body kept in
+// Response.RawBody, unless the Client's RawBodyLimit is set.
+const DefaultRawBodyLimit = 64 * 1024
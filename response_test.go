@@ -0,0 +1,738 @@
+package sawyer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer/hypermedia"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestRel(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Link", `</user?page=2>; rel="next"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"login": "sawyer", "_links": {"repos": {"href": "/user/repos"}}}`))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestHALUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+
+	repos, err := res.Rel("repos")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, setup.Server.URL+"/user/repos?a=1&b=1", repos.URL.String())
+
+	next, err := res.Rel("next")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, setup.Server.URL+"/user?a=1&b=1&page=2", next.URL.String())
+
+	_, err = res.Rel("missing")
+	assert.Equal(t, "No missing relation found", err.Error())
+}
+
+func TestRelFromLinkHeaderOnly(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Link", `</user?page=2>; rel="next"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"login": "sawyer", "_links": {"repos": {"href": "/user/repos"}}}`))
+	})
+
+	setup.Client.Relations = LinkHeaderRelations
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestHALUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+
+	_, err = res.Rel("repos")
+	assert.Equal(t, "No repos relation found", err.Error())
+
+	_, err = res.Rel("next")
+	assert.Equal(t, nil, err)
+}
+
+func TestRelWithoutClient(t *testing.T) {
+	res := ResponseError(nil)
+	_, err := res.Rel("next")
+	assert.Equal(t, "No client for this response", err.Error())
+}
+
+func TestLinks(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Link", `</user/repos?page=2>; rel="next last", </user/repos?page=1>; rel="first"`)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, err := setup.Client.NewRequest("user/repos")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	links := res.Links()
+	assert.Equal(t, 3, len(links))
+	assert.Equal(t, "/user/repos?page=2", links["next"])
+	assert.Equal(t, "/user/repos?page=2", links["last"])
+	assert.Equal(t, "/user/repos?page=1", links["first"])
+
+	links["next"] = "changed"
+	assert.Equal(t, "changed", res.Links()["next"])
+}
+
+func TestLinksWithoutHeader(t *testing.T) {
+	res := ResponseError(nil)
+	assert.Equal(t, 0, len(res.Links()))
+}
+
+func TestPagination(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("a"))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		links := `</users?page=1>; rel="first", </users?page=3>; rel="last"`
+		if page > 1 {
+			links += fmt.Sprintf(`, </users?page=%d>; rel="prev"`, page-1)
+		}
+		if page < 3 {
+			links += fmt.Sprintf(`, </users?page=%d>; rel="next"`, page+1)
+		}
+
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		head.Set("Link", links)
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `[{"id": %d}]`, page)
+	})
+
+	setup.Client.Query.Set("page", "1")
+	setup.Client.Header.Set("X-Token", "secret")
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	ids := []int{}
+	res := req.Get()
+	for {
+		users := []TestUser{}
+		assert.Equal(t, nil, res.Decode(&users))
+		ids = append(ids, users[0].Id)
+
+		next, ok := res.NextPage()
+		if !ok {
+			break
+		}
+		res = next.Get()
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+
+	prev, ok := res.PrevPage()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "2", prev.Query.Get("page"))
+
+	first, ok := res.FirstPage()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "1", first.Query.Get("page"))
+
+	last, ok := res.LastPage()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "3", last.Query.Get("page"))
+}
+
+func TestHALResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/hal+json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer",
+			"_links": {"self": {"href": "/user"}},
+			"_embedded": {"followers": [{"id": 2, "login": "huck"}]}}`))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+
+	followers := []TestUser{}
+	assert.Equal(t, nil, res.Embedded("followers", &followers))
+	assert.Equal(t, 1, len(followers))
+	assert.Equal(t, "huck", followers[0].Login)
+
+	self, err := res.Rel("self")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, setup.Server.URL+"/user?a=1&b=1", self.URL.String())
+
+	err = res.Embedded("missing", &followers)
+	assert.Equal(t, "No missing embedded resource found", err.Error())
+}
+
+func TestRawBodyOnDecodeError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "one", "login": "sawyer"}`))
+	})
+
+	setup.Client.BufferResponseOnError = true
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.NotEqual(t, nil, res.Decode(user))
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, `{"id": "one", "login": "sawyer"}`, string(res.RawBody))
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestRawBodyLimit(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "one", "login": "sawyer"}`))
+	})
+
+	setup.Client.BufferResponseOnError = true
+	setup.Client.RawBodyLimit = 5
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.NotEqual(t, nil, res.Decode(&TestUser{}))
+	assert.Equal(t, `{"id"`, string(res.RawBody))
+}
+
+func TestNoRawBodyOnSuccess(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	setup.Client.BufferResponseOnError = true
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(&TestUser{}))
+	assert.Equal(t, 0, len(res.RawBody))
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+type TestHALUser struct {
+	Login string `json:"login"`
+	*hypermedia.HALResource
+}
+
+func TestErr(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/404", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "not found"}`))
+	})
+
+	req, err := setup.Client.NewRequest("404")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	apierr := &TestError{}
+	assert.Equal(t, nil, res.Decode(apierr))
+	assert.Equal(t, "404 Not Found", res.Err().Error())
+
+	var e *ApiError
+	assert.Equal(t, true, errors.As(res.Err(), &e))
+	assert.Equal(t, 404, e.StatusCode)
+	assert.Equal(t, `{"message": "not found"}`, string(e.Body))
+	assert.Equal(t, apierr, e.Resource)
+	assert.Equal(t, "", res.Error())
+}
+
+func TestErrWithoutDecode(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/500", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("oops"))
+	})
+
+	req, err := setup.Client.NewRequest("500")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	e, ok := res.Err().(*ApiError)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 500, e.StatusCode)
+	assert.Equal(t, "oops", string(e.Body))
+	assert.Equal(t, nil, e.Resource)
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestErrOnSuccess(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.Get().Err())
+
+	failure := errors.New("connection refused")
+	assert.Equal(t, failure, ResponseError(failure).Err())
+}
+
+func TestErrorClasses(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		w.WriteHeader(status)
+	})
+
+	setup.Mux.HandleFunc("/truncated", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "login"`))
+	})
+
+	tests := []struct {
+		status int
+		client bool
+		server bool
+	}{
+		{200, false, false},
+		{304, false, false},
+		{404, true, false},
+		{429, true, false},
+		{500, false, true},
+		{503, false, true},
+	}
+
+	for _, test := range tests {
+		req, err := setup.Client.NewRequest(fmt.Sprintf("status?status=%d", test.status))
+		assert.Equal(t, nil, err)
+
+		res := req.Get()
+		assert.Equal(t, test.status, res.StatusCode)
+		assert.Equal(t, false, res.IsTransportError())
+		assert.Equal(t, false, res.IsDecodeError())
+		assert.Equal(t, test.client, res.IsClientError())
+		assert.Equal(t, test.server, res.IsServerError())
+		assert.Equal(t, test.client || test.server, res.AnyError())
+	}
+
+	req, err := setup.Client.NewRequest("truncated")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.NotEqual(t, nil, res.Decode(&TestUser{}))
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, true, res.IsDecodeError())
+	assert.Equal(t, false, res.IsTransportError())
+	assert.Equal(t, true, res.AnyError())
+
+	res = ResponseError(errors.New("connection refused"))
+	assert.Equal(t, true, res.IsTransportError())
+	assert.Equal(t, false, res.IsDecodeError())
+	assert.Equal(t, false, res.IsClientError())
+	assert.Equal(t, false, res.IsServerError())
+	assert.Equal(t, true, res.AnyError())
+}
+
+func TestUndecodableErrorResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/500", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<h1>Oops</h1>"))
+	})
+
+	req, err := setup.Client.NewRequest("500")
+	assert.Equal(t, nil, err)
+
+	apierr := &TestError{}
+	res := req.Get()
+	assert.Equal(t, true, res.IsApiError())
+	assert.Equal(t, true, res.IsServerError())
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, "<h1>Oops</h1>", string(res.RawBody))
+	assert.Equal(t, `Unable to decode 500 Internal Server Error response of type "text/html"`, res.Error())
+	assert.Equal(t, res.ResponseError, res.Decode(apierr))
+	assert.Equal(t, "", apierr.Message)
+
+	e, ok := res.Err().(*ApiError)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "<h1>Oops</h1>", string(e.Body))
+	assert.Equal(t, nil, e.Resource)
+}
+
+func TestEmptyErrorResponse(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/500", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req, err := setup.Client.NewRequest("500")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 0, len(res.RawBody))
+}
+
+func TestTeeResponseBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}` + "\n\n"))
+	})
+
+	setup.Client.TeeResponseBody = true
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, true, res.BodyClosed)
+
+	body, err := ioutil.ReadAll(res.Body)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"id": 1, "login": "sawyer"}`+"\n\n", string(body))
+	assert.Equal(t, nil, res.Body.Close())
+	assert.Equal(t, nil, res.Decode(user))
+}
+
+func TestAllowedMethods(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "OPTIONS", r.Method)
+		head := w.Header()
+		head.Add("Allow", "get, HEAD ,Patch")
+		head.Add("Allow", "options")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Options()
+	assert.Equal(t, []string{"GET", "HEAD", "PATCH", "OPTIONS"}, res.AllowedMethods())
+	assert.Equal(t, 0, len(ResponseError(nil).AllowedMethods()))
+}
+
+func TestResponseHeaderHelpers(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		head := w.Header()
+		head.Set("X-Request-Id", "abc123")
+		head.Add("Warning", `199 - "first"`)
+		head.Add("Warning", `199 - "second"`)
+		head["X-Empty"] = []string{""}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, "abc123", res.HeaderValue("x-request-id"))
+	assert.Equal(t, []string{"abc123"}, res.HeaderValues("X-Request-Id"))
+	assert.Equal(t, `199 - "first"`, res.HeaderValue("Warning"))
+	assert.Equal(t, []string{`199 - "first"`, `199 - "second"`}, res.HeaderValues("warning"))
+	assert.Equal(t, true, res.HasHeader("x-empty"))
+	assert.Equal(t, false, res.HasHeader("X-Missing"))
+	assert.Equal(t, "", res.HeaderValue("X-Missing"))
+
+	res = ResponseError(nil)
+	assert.Equal(t, "", res.HeaderValue("X-Request-Id"))
+	assert.Equal(t, 0, len(res.HeaderValues("Warning")))
+	assert.Equal(t, false, res.HasHeader("X-Request-Id"))
+}
+
+func TestBytesRead(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	payload := `{"id": 1, "login": "sawyer"}`
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(payload))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, int64(0), res.BytesRead)
+	assert.Equal(t, nil, res.Decode(&TestUser{}))
+	assert.Equal(t, int64(len(payload)), res.BytesRead)
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res, err = req.GetStream()
+	assert.Equal(t, nil, err)
+
+	buf := make([]byte, 10)
+	n, err := io.ReadFull(res.Body, buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(n), res.BytesRead)
+
+	assert.Equal(t, nil, res.Consume())
+	assert.Equal(t, int64(len(payload)), res.BytesRead)
+}
+
+func TestFallbackDecoder(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.NotEqual(t, nil, res.Decode(user))
+
+	mtype, err := mediatype.Parse("application/json")
+	assert.Equal(t, nil, err)
+	setup.Client.SetFallbackDecoder(mtype)
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res = req.Get()
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, "text/plain", res.MediaType.String())
+}
+
+func TestDecodeWithoutContentType(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		// a nil value keeps the server from sniffing a content type
+		w.Header()["Content-Type"] = nil
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	res := req.Get()
+	assert.Equal(t, "No media type for this response", res.Decode(user).Error())
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	req.SetHeader("Accept", "application/json, application/xml")
+
+	res = req.Get()
+	assert.Equal(t, "No media type for this response", res.Decode(user).Error())
+
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	req.SetHeader("Accept", "application/json")
+
+	res = req.Get()
+	assert.Equal(t, (*mediatype.MediaType)(nil), res.MediaType)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+}
+
+func TestExpectStatus(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message": "boom"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	req.ExpectStatus(200, 201)
+
+	user := &TestUser{}
+	res := req.Post()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	req.ExpectStatus(200, 201)
+
+	res = req.Delete()
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, "Unexpected status 500, expected [200 201]", res.Error())
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, `{"message": "boom"}`, string(res.RawBody))
+}
+
+func TestUseJSONNumber(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 9007199254740993, "login": "sawyer"}`))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := map[string]interface{}{}
+	assert.Equal(t, nil, req.Get().Decode(&user))
+	assert.Equal(t, float64(9007199254740992), user["id"])
+
+	setup.Client.UseJSONNumber = true
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user = map[string]interface{}{}
+	assert.Equal(t, nil, req.Get().Decode(&user))
+	assert.Equal(t, json.Number("9007199254740993"), user["id"])
+
+	setup.Mux.HandleFunc("/hal", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(`{"id": 9007199254740993, "_links": {"self": {"href": "/hal"}}}`))
+	})
+
+	req, err = setup.Client.NewRequest("hal")
+	assert.Equal(t, nil, err)
+
+	user = map[string]interface{}{}
+	assert.Equal(t, nil, req.Get().Decode(&user))
+	assert.Equal(t, json.Number("9007199254740993"), user["id"])
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "login": "sawyer", "email": "sawyer@example.com"}`))
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	user := &TestUser{}
+	assert.Equal(t, nil, req.Get().Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+
+	setup.Client.DisallowUnknownFields = true
+	req, err = setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.NotEqual(t, nil, res.Decode(&TestUser{}))
+	assert.Equal(t, `json: unknown field "email"`, res.Error())
+}
+
+func TestResponseMediaType(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.github.v3+json; charset=ISO-8859-1")
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	setup.Mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, "application/vnd.github.v3+json", res.MediaType.Type)
+	assert.Equal(t, "json", res.MediaType.Format)
+	assert.Equal(t, "v3", res.MediaType.Version)
+	assert.Equal(t, "ISO-8859-1", res.MediaType.Params["charset"])
+
+	req, err = setup.Client.NewRequest("empty")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, (*mediatype.MediaType)(nil), req.Get().MediaType)
+}
@@ -0,0 +1,176 @@
+package sawyer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy decides if a failed request is sent again.  ShouldRetry is
+// called after each attempt, counting from 1, with the response status (0 if
+// the request failed) and any transport error.  Backoff returns how long to
+// wait before the next attempt.
+type RetryPolicy interface {
+	ShouldRetry(attempt int, status int, err error) bool
+	Backoff(attempt int) time.Duration
+}
+
+// ExponentialBackoff retries transport errors, 429 Too Many Requests, and
+// 5xx responses up to MaxRetries times.  The delay starts at BaseDelay and
+// doubles after each attempt, up to MaxDelay if it is set.
+type ExponentialBackoff struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+func (b *ExponentialBackoff) ShouldRetry(attempt int, status int, err error) bool {
+	if attempt > b.MaxRetries {
+		return false
+	}
+	return err != nil || status == http.StatusTooManyRequests || status >= 500
+}
+
+func (b *ExponentialBackoff) Backoff(attempt int) time.Duration {
+	delay := b.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if b.MaxDelay > 0 && delay >= b.MaxDelay {
+			break
+		}
+	}
+
+	if b.MaxDelay > 0 && delay > b.MaxDelay {
+		return b.MaxDelay
+	}
+	return delay
+}
+
+// SetRetry sets the policy for retrying requests built by this Client.  A
+// nil policy disables retries.  Requests with a body are only retried if the
+// body can be read again, like the ones set with SetBody, or set with
+// SetBodyReader from an io.Seeker.  Otherwise, the request fails instead of
+// being retried.
+func (c *Client) SetRetry(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// roundTrip sends the request, retrying it according to the Client's
+// RetryPolicy.  A Retry-After header on a 429 or 503 response is waited for
+// instead of the policy's backoff.
+func (r *Request) roundTrip(httpreq *http.Request) (*http.Response, error) {
+	var policy RetryPolicy
+	if r.client != nil {
+		policy = r.client.retry
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := r.sign(httpreq); err != nil {
+			return nil, err
+		}
+
+		httpres, err := r.Client.Do(httpreq)
+		if policy == nil {
+			return httpres, err
+		}
+
+		status := 0
+		if httpres != nil {
+			status = httpres.StatusCode
+		}
+
+		if !policy.ShouldRetry(attempt, status, err) {
+			return httpres, err
+		}
+
+		if !r.rewindable(httpreq) {
+			if httpres != nil {
+				httpres.Body.Close()
+			}
+			return nil, errNotRewindable
+		}
+
+		delay, ok := retryAfter(httpres, r.client.now())
+		if !ok {
+			delay = policy.Backoff(attempt)
+		}
+
+		if httpres != nil {
+			io.Copy(ioutil.Discard, httpres.Body)
+			httpres.Body.Close()
+		}
+
+		if err := sleep(httpreq.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		if err := r.rewindBody(httpreq); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for the given duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rewindable reports whether the request's body can be sent again, from
+// GetBody, or by seeking back a reader set with SetBodyReader.
+func (r *Request) rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil || r.rewind != nil
+}
+
+// rewindBody resets the request's body before it is sent again.
+func (r *Request) rewindBody(req *http.Request) (err error) {
+	if req.GetBody != nil {
+		req.Body, err = req.GetBody()
+		return err
+	}
+
+	if r.rewind != nil {
+		return r.rewind()
+	}
+	return nil
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, given
+// either in seconds or as an HTTP date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := res.Header.Get(retryAfterHeader)
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+var errNotRewindable = errors.New("Unable to retry request, its body cannot be read again")
+
+const retryAfterHeader = "Retry-After"
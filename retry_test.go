@@ -0,0 +1,271 @@
+package sawyer
+
+import (
+	"github.com/bmizerany/assert"
+	"github.com/lostisland/go-sawyer/mediatype"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryServerError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"id":1,"login":"sawyer"}`+"\n", string(body))
+
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+
+	setup.Client.SetRetry(&ExponentialBackoff{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	mtype, _ := mediatype.Parse("application/json")
+	assert.Equal(t, nil, req.SetBody(mtype, &TestUser{1, "sawyer"}))
+
+	user := &TestUser{}
+	res := req.Post()
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, nil, res.Decode(user))
+	assert.Equal(t, "sawyer", user.Login)
+	assert.Equal(t, 3, requests)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	setup.Client.SetRetry(&ExponentialBackoff{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, 500, res.StatusCode)
+	assert.Equal(t, true, res.IsApiError())
+	assert.Equal(t, 3, requests)
+}
+
+func TestRetryTransportError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		if requests == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			assert.Equal(t, nil, err)
+			conn.Close()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}`))
+	})
+
+	setup.Client.SetRetry(&ExponentialBackoff{MaxRetries: 1, BaseDelay: time.Millisecond})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, false, res.IsError())
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestRetryAfter(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// the backoff would outlast the test, unless Retry-After is used instead
+	setup.Client.SetRetry(&ExponentialBackoff{MaxRetries: 1, BaseDelay: time.Hour})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestRetryStreamingBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	setup.Client.SetRetry(&ExponentialBackoff{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	req.SetBodyReader("text/plain", io.MultiReader(strings.NewReader("sawyer")), -1)
+	res := req.Post()
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, "Unable to retry request, its body cannot be read again", res.Error())
+	assert.Equal(t, 1, requests)
+}
+
+func TestRetrySeekableBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "sawyer", string(body))
+
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	setup.Client.SetRetry(&ExponentialBackoff{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	body := &closingReader{strings.NewReader("--sawyer"), make(chan bool, 1)}
+	body.Seek(2, io.SeekStart)
+	req.SetBodyReader("text/plain", body, -1)
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, 2, requests)
+
+	select {
+	case <-body.closed:
+	default:
+		t.Fatal("request body was not closed")
+	}
+
+	// a seeker without ReadAt is rewound before the retry
+	requests = 0
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	seeker := &seekOnlyReader{strings.NewReader("--sawyer")}
+	seeker.Seek(2, io.SeekStart)
+	req.SetBodyReader("text/plain", seeker, -1)
+	assert.Equal(t, (func() (io.ReadCloser, error))(nil), req.GetBody)
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+// seekOnlyReader hides the ReadAt method of its reader.
+type seekOnlyReader struct {
+	r *strings.Reader
+}
+
+func (r *seekOnlyReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *seekOnlyReader) Seek(offset int64, whence int) (int64, error) {
+	return r.r.Seek(offset, whence)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	policy := &ExponentialBackoff{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+	assert.Equal(t, 5*time.Second, policy.Backoff(100))
+
+	assert.Equal(t, true, policy.ShouldRetry(3, 500, nil))
+	assert.Equal(t, false, policy.ShouldRetry(4, 500, nil))
+	assert.Equal(t, false, policy.ShouldRetry(1, 404, nil))
+	assert.Equal(t, true, policy.ShouldRetry(1, 429, nil))
+}
+
+func TestAutoIdempotencyKey(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	var keys []string
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	setup.Client.AutoIdempotencyKey = true
+	setup.Client.SetRetry(&ExponentialBackoff{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		req, err := setup.Client.NewRequest("users")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+		assert.Equal(t, 201, req.Post().StatusCode)
+	}
+
+	assert.Equal(t, 4, len(keys))
+	assert.Equal(t, 36, len(keys[0]))
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[2], keys[3])
+	assert.NotEqual(t, keys[0], keys[2])
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	req.SetIdempotencyKey("create-sawyer")
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, []string{"create-sawyer", "create-sawyer"}, keys[4:])
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 201, req.Put().StatusCode)
+	assert.Equal(t, []string{"", ""}, keys[6:])
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, "", req.Header.Get("Idempotency-Key"))
+	assert.Equal(t, 201, req.Post().StatusCode)
+	assert.Equal(t, 12, len(keys))
+	assert.Equal(t, keys[8], keys[9])
+	assert.Equal(t, keys[10], keys[11])
+	assert.NotEqual(t, keys[8], keys[10])
+}
@@ -1,12 +1,21 @@
 package sawyer
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/lostisland/go-sawyer/hypermedia"
 	"github.com/lostisland/go-sawyer/mediatype"
 	"io"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"path"
 	"strings"
+	"sync"
+	"time"
 )
 
 // The default httpClient used if one isn't specified.
@@ -18,7 +27,102 @@ type Client struct {
 	HttpClient *http.Client
 	Endpoint   *url.URL
 	Header     http.Header
-	Query      url.Values
+
+	// Query holds default query parameters.  Change it with SetQueryParam
+	// once the Client is in use by several goroutines.
+	Query url.Values
+
+	// Relations picks where a Response looks for link relations.  New enables
+	// all sources.
+	Relations RelationSource
+
+	// BufferResponseOnError keeps the raw body of responses that fail to
+	// decode in Response.RawBody, up to RawBodyLimit bytes.
+	BufferResponseOnError bool
+	RawBodyLimit          int64
+
+	// TeeResponseBody keeps a copy of the body while a Response is decoded,
+	// and replaces Response.Body with it, so that it can be read again.
+	TeeResponseBody bool
+
+	// Logger logs the method and URL of each request, and then its status
+	// and duration.
+	Logger Logger
+
+	// LogRequestBodies also logs the body of each request that is buffered,
+	// like one set with SetBody or SetRawBody, with the values of any fields
+	// set with SetSensitiveFields redacted.  Bodies streamed from a reader are
+	// logged as a placeholder, without being read.
+	LogRequestBodies bool
+
+	// TraceTiming records how long each phase of a request takes in
+	// Response.Timing.
+	TraceTiming bool
+
+	// MaxResponseBytes limits how much of a response body is read, after it
+	// is decompressed.  Reading past it fails with an error.  Zero means no
+	// limit.
+	MaxResponseBytes int64
+
+	// Cacher stores GET responses with an ETag, a Last-Modified date, or a
+	// freshness lifetime.  Fresh responses are replayed without a request, and
+	// stale ones when the server responds with 304 Not Modified.  Streamed
+	// responses, from GetStream or DownloadTo, are not cached.
+	Cacher Cacher
+
+	// RevalidateTransparently replays a stale cached response when the
+	// server responds with 304 Not Modified.  If it is unset, the 304
+	// response is returned instead, and Response.NotModified reports it.  New
+	// sets it.
+	RevalidateTransparently bool
+
+	// CachePrivateResponses allows responses marked Cache-Control: private to
+	// be cached.  Leave it unset if the Client is shared between users.
+	CachePrivateResponses bool
+
+	// UseJSONNumber decodes JSON numbers into interface{} values as
+	// json.Number instead of float64, so that large IDs keep their precision.
+	UseJSONNumber bool
+
+	// DisallowUnknownFields fails to decode JSON objects with fields that the
+	// output struct does not have.
+	DisallowUnknownFields bool
+
+	// AutoIdempotencyKey gives POST and PATCH requests a random
+	// Idempotency-Key header when retries are enabled, unless they have one,
+	// so that a retried request is not repeated by the server.
+	AutoIdempotencyKey bool
+
+	// AllowCrossHostAuth keeps the Authorization and Cookie headers on
+	// redirects to another host or scheme.  They are dropped by default.
+	AllowCrossHostAuth bool
+
+	// ThrottleOnRateLimit makes requests wait for the rate limit to reset
+	// once a response reports that no requests remain.
+	ThrottleOnRateLimit bool
+
+	username      string
+	password      string
+	token         string
+	retry         RetryPolicy
+	middleware    []Middleware
+	redirect      func(*http.Request, []*http.Request) error
+	jar           http.CookieJar
+	beforeRequest []func(*Request) error
+	afterResponse []func(*Response)
+	decodeHook    func(*Response, interface{}) error
+	rate          *rateLimitState
+	fallback      *mediatype.MediaType
+	pathPrefix    string
+	sensitive     map[string]bool
+	transport     *http.Transport
+	transportOpts []func(*http.Transport)
+	signer        Signer
+	tokens        TokenSource
+	reauth        func(*Client) error
+	auth          *authState
+	queryMutex    *sync.RWMutex
+	clock         func() time.Time
 }
 
 // New returns a new Client with a given a URL and an optional client.
@@ -31,23 +135,295 @@ func New(endpoint *url.URL, client *http.Client) *Client {
 		endpoint.Path = endpoint.Path + "/"
 	}
 
-	return &Client{client, endpoint, make(http.Header), endpoint.Query()}
+	return &Client{
+		HttpClient: client,
+		Endpoint:   endpoint,
+		Header:     make(http.Header),
+		Query:      endpoint.Query(),
+		Relations:  LinkHeaderRelations | BodyRelations,
+		rate:       &rateLimitState{},
+		auth:       new(authState),
+		queryMutex: new(sync.RWMutex),
+
+		RevalidateTransparently: true,
+	}
 }
 
 // NewFromString returns a new Client given a string URL and an optional client.
+// The URL must be absolute, with a scheme and a host.
 func NewFromString(endpoint string, client *http.Client) (*Client, error) {
 	e, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := validateEndpoint(e); err != nil {
+		return nil, err
+	}
 	return New(e, client), nil
 }
 
+// NewUnixClient returns a new Client that sends requests to a server listening
+// on a Unix domain socket, like the Docker daemon.  Paths are resolved against
+// the given base URL, which defaults to "http://localhost/".
+//
+//	client, err := sawyer.NewUnixClient("/var/run/docker.sock", "http://localhost/v1.41/")
+func NewUnixClient(socketPath, baseURL string) (*Client, error) {
+	if len(baseURL) == 0 {
+		baseURL = "http://localhost/"
+	}
+
+	c, err := NewFromString(baseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.configureTransport(func(t *http.Transport) {
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	})
+	return c, nil
+}
+
+// Clone returns a copy of the Client that can be changed without affecting
+// it.  The HttpClient, Cacher, Logger, cookie jar, Signer, TokenSource, and
+// RetryPolicy are shared, and so are the reauth, redirect, and decode hook
+// functions, the fallback media type, and the sensitive fields, which are
+// only ever replaced.  The header, default query, middleware, and hooks are
+// copied, so adding to the copy's does not change the original's.  A
+// transport set with SetTransport is copied, without its idle connections.
+func (c *Client) Clone() *Client {
+	c2 := new(Client)
+	*c2 = *c
+	c2.username, c2.password, c2.token, _ = c.credentials()
+	c2.auth = new(authState)
+
+	if c.Endpoint != nil {
+		endpoint := *c.Endpoint
+		if c.Endpoint.User != nil {
+			user := *c.Endpoint.User
+			endpoint.User = &user
+		}
+		c2.Endpoint = &endpoint
+	}
+
+	c2.Header = c.Header.Clone()
+	c2.Query = c.cloneQuery()
+	c2.queryMutex = new(sync.RWMutex)
+	c2.middleware = append([]Middleware{}, c.middleware...)
+	c2.beforeRequest = append([]func(*Request) error{}, c.beforeRequest...)
+	c2.afterResponse = append([]func(*Response){}, c.afterResponse...)
+	c2.rate = c.rate.clone()
+	c2.transportOpts = append([]func(*http.Transport){}, c.transportOpts...)
+	if c.transport != nil {
+		c2.transport = c.transport.Clone()
+	}
+	return c2
+}
+
+// BaseURL returns a copy of the Endpoint that requests are resolved against.
+func (c *Client) BaseURL() *url.URL {
+	u := *c.Endpoint
+	return &u
+}
+
+// SetBaseURL repoints the Client at another absolute URL, such as to switch
+// API versions.  Default query parameters are kept, and any in the new URL
+// are added to them.
+func (c *Client) SetBaseURL(u *url.URL) error {
+	if err := validateEndpoint(u); err != nil {
+		return err
+	}
+
+	endpoint := *u
+	if !strings.HasSuffix(endpoint.Path, "/") {
+		endpoint.Path = endpoint.Path + "/"
+	}
+
+	unlock := c.lockQuery(true)
+	defer unlock()
+
+	if c.Query == nil {
+		c.Query = make(url.Values)
+	}
+	for key, values := range endpoint.Query() {
+		c.Query[key] = values
+	}
+	c.Endpoint = &endpoint
+	return nil
+}
+
+// SetPathPrefix adds a path, such as an API version like "/v3", between the
+// Endpoint and the relative URLs given to NewRequest, so that "user" resolves
+// to "/v3/user".  Absolute URLs, and paths starting with a slash, are not
+// prefixed.  An empty prefix removes it.
+func (c *Client) SetPathPrefix(prefix string) {
+	c.pathPrefix = prefix
+}
+
+// base returns the Endpoint with the path prefix added.
+func (c *Client) base() *url.URL {
+	if len(strings.Trim(c.pathPrefix, "/")) == 0 {
+		return c.Endpoint
+	}
+
+	base := *c.Endpoint
+	base.Path = path.Join("/", base.Path, c.pathPrefix) + "/"
+	base.RawPath = ""
+	return &base
+}
+
+// SetCookieJar sets the jar that stores and sends cookies for requests built
+// by this Client, without changing HttpClient.
+func (c *Client) SetCookieJar(jar http.CookieJar) {
+	c.jar = jar
+}
+
+// EnableCookies keeps cookies between requests in an in-memory jar.
+func (c *Client) EnableCookies() {
+	// cookiejar.New never fails without options
+	jar, _ := cookiejar.New(nil)
+	c.SetCookieJar(jar)
+}
+
+// SetQueryParam sets a default query parameter for requests built by this
+// Client.  Unlike changing Query directly, it is safe to call while requests
+// are being built in other goroutines.
+func (c *Client) SetQueryParam(key, value string) {
+	unlock := c.lockQuery(true)
+	defer unlock()
+
+	if c.Query == nil {
+		c.Query = make(url.Values)
+	}
+	c.Query.Set(key, value)
+}
+
+// GetQueryParam returns the default query parameter for the given key.
+func (c *Client) GetQueryParam(key string) string {
+	unlock := c.lockQuery(false)
+	defer unlock()
+	return c.Query.Get(key)
+}
+
+// lockQuery locks the Client's Query for writing or reading, and returns the
+// function that unlocks it.  Clients not built with New are not locked.
+func (c *Client) lockQuery(write bool) func() {
+	switch {
+	case c.queryMutex == nil:
+		return func() {}
+	case write:
+		c.queryMutex.Lock()
+		return c.queryMutex.Unlock
+	default:
+		c.queryMutex.RLock()
+		return c.queryMutex.RUnlock
+	}
+}
+
+func (c *Client) cloneQuery() url.Values {
+	unlock := c.lockQuery(false)
+	defer unlock()
+	return cloneValues(c.Query)
+}
+
+// BasicAuth sets credentials that are sent with every Request built by this
+// Client.  Empty credentials are ignored.
+func (c *Client) BasicAuth(user, pass string) {
+	unlock := c.lockAuth(true)
+	defer unlock()
+	c.username = user
+	c.password = pass
+}
+
+// SetBearerToken sets an OAuth token that is sent with every Request built by
+// this Client.  It takes precedence over BasicAuth credentials.
+func (c *Client) SetBearerToken(token string) {
+	unlock := c.lockAuth(true)
+	defer unlock()
+	c.token = token
+}
+
+// SetDefaultHeader sets a header that is sent with every Request built by
+// this Client.
+func (c *Client) SetDefaultHeader(key, value string) {
+	c.Header.Set(key, value)
+}
+
+// SetAccept sets the default Accept header sent with every Request built by
+// this Client.
+func (c *Client) SetAccept(mtype *mediatype.MediaType) {
+	c.SetAcceptTypes(mtype)
+}
+
+// SetAcceptTypes sets a default Accept header listing several media types.
+// Quality values are taken from each type's "q" parameter:
+//
+//	json, _ := mediatype.Parse("application/json")
+//	xml, _ := mediatype.Parse("application/xml; q=0.5")
+//	client.SetAcceptTypes(json, xml)
+func (c *Client) SetAcceptTypes(mtypes ...*mediatype.MediaType) {
+	c.Header.Set(acceptHeader, acceptString(mtypes))
+}
+
+// SetFallbackDecoder sets the media type used to decode responses with a
+// content type that has no decoder, or no content type at all.  By default,
+// decoding them fails.
+//
+//	mtype, _ := mediatype.Parse("application/json")
+//	client.SetFallbackDecoder(mtype)
+func (c *Client) SetFallbackDecoder(mtype *mediatype.MediaType) {
+	c.fallback = mtype
+}
+
+// httpClient returns the *http.Client used by new requests.  It is a copy of
+// HttpClient with the Client's middleware, redirect policy, cookie jar, and
+// transport.
+func (c *Client) httpClient() *http.Client {
+	client := new(http.Client)
+	if c.HttpClient != nil {
+		*client = *c.HttpClient
+	}
+
+	policy := client.CheckRedirect
+	if c.redirect != nil {
+		policy = c.redirect
+	}
+	client.CheckRedirect = c.checkRedirect(policy)
+
+	if c.jar != nil {
+		client.Jar = c.jar
+	}
+
+	if c.transport != nil {
+		client.Transport = c.transport
+	}
+
+	if len(c.middleware) > 0 {
+		transport := client.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+
+		for i := len(c.middleware) - 1; i >= 0; i-- {
+			transport = c.middleware[i](transport)
+		}
+		client.Transport = transport
+	}
+	return client
+}
+
 // ResolveReference resolves a URI reference to an absolute URI from an absolute
-// base URI.  It also merges the query values.
+// base URI, with the Client's path prefix.  It also merges the query values.
+// An absolute reference keeps its own scheme and host.
 func (c *Client) ResolveReference(u *url.URL) *url.URL {
-	absurl := c.Endpoint.ResolveReference(u)
+	absurl := c.base().ResolveReference(u)
+	unlock := c.lockQuery(false)
+	defer unlock()
+
 	if len(c.Query) > 0 {
 		absurl.RawQuery = mergeQueries(c.Query, absurl.Query())
 	}
@@ -64,6 +440,25 @@ func (c *Client) ResolveReferenceString(rawurl string) (string, error) {
 	return c.ResolveReference(u).String(), nil
 }
 
+// RelationSource is a set of places that a Response looks for link relations.
+type RelationSource int
+
+const (
+	// LinkHeaderRelations are parsed from the Link response header.
+	LinkHeaderRelations RelationSource = 1 << iota
+
+	// BodyRelations come from a decoded resource that is a
+	// hypermedia.HypermediaResource, such as a HAL resource with _links.
+	BodyRelations
+)
+
+func validateEndpoint(endpoint *url.URL) error {
+	if len(endpoint.Scheme) == 0 || len(endpoint.Host) == 0 {
+		return fmt.Errorf("Invalid endpoint %q: it needs a scheme and a host, like https://api.example.com", endpoint.String())
+	}
+	return nil
+}
+
 func mergeQueries(queries ...url.Values) string {
 	merged := make(url.Values)
 	for _, q := range queries {
@@ -78,6 +473,26 @@ func mergeQueries(queries ...url.Values) string {
 	return merged.Encode()
 }
 
+func cloneValues(values url.Values) url.Values {
+	if values == nil {
+		return nil
+	}
+
+	clone := make(url.Values, len(values))
+	for key, value := range values {
+		clone[key] = append([]string(nil), value...)
+	}
+	return clone
+}
+
+func acceptString(mtypes []*mediatype.MediaType) string {
+	types := make([]string, len(mtypes))
+	for i, mtype := range mtypes {
+		types[i] = mtype.String()
+	}
+	return strings.Join(types, ", ")
+}
+
 func init() {
 	mediatype.AddDecoder("json", func(r io.Reader) mediatype.Decoder {
 		return json.NewDecoder(r)
@@ -85,4 +500,18 @@ func init() {
 	mediatype.AddEncoder("json", func(w io.Writer) mediatype.Encoder {
 		return json.NewEncoder(w)
 	})
+	mediatype.AddDecoder("xml", func(r io.Reader) mediatype.Decoder {
+		return xml.NewDecoder(r)
+	})
+	mediatype.AddEncoder("xml", func(w io.Writer) mediatype.Encoder {
+		return xml.NewEncoder(w)
+	})
+	mediatype.AddDecoder(halType, func(r io.Reader) mediatype.Decoder {
+		return hypermedia.NewHALDecoder(r)
+	})
+	mediatype.AddDecoder("ndjson", func(r io.Reader) mediatype.Decoder {
+		return json.NewDecoder(r)
+	})
 }
+
+const halType = "application/hal+json"
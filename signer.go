@@ -0,0 +1,30 @@
+package sawyer
+
+import (
+	"net/http"
+)
+
+// A Signer signs each request just before it is sent, once its headers and
+// body are final, such as for HMAC or AWS Signature Version 4 APIs.  The body
+// can be read for hashing from GetBody, if it is set, which returns a new copy
+// of it each time.  GetBody is set for bodies from SetBody, SetRawBody, and
+// SetFormBody, and for readers with a ReadAt method, like a *bytes.Reader or
+// an *os.File.  Other streamed bodies have no GetBody, and cannot be hashed
+// without being consumed.  Retried requests are signed again.
+type Signer interface {
+	Sign(req *http.Request) error
+}
+
+// SetSigner sets the Signer for requests built by this Client.  If it returns
+// an error, the request is not sent, and the error is returned in the
+// Response.
+func (c *Client) SetSigner(s Signer) {
+	c.signer = s
+}
+
+func (r *Request) sign(httpreq *http.Request) error {
+	if r.client == nil || r.client.signer == nil {
+		return nil
+	}
+	return r.client.signer.Sign(httpreq)
+}
@@ -0,0 +1,115 @@
+package sawyer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := hmacSignature("secret", r.Method, r.URL.RequestURI(), body)
+		if r.Header.Get("X-Signature") != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+	assert.Equal(t, 401, req.Post().StatusCode)
+
+	setup.Client.SetSigner(&hmacSigner{"secret"})
+
+	req, err = setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, req.SetJSONBody(&TestUser{1, "sawyer"}))
+	assert.Equal(t, 201, req.Post().StatusCode)
+}
+
+func TestSignerWithSeekableBody(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"login": "sawyer"}`, string(body))
+		if r.Header.Get("X-Signature") != hmacSignature("secret", r.Method, r.URL.RequestURI(), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	setup.Client.SetSigner(&hmacSigner{"secret"})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	body := []byte(`{"login": "sawyer"}`)
+	req.SetBodyReader("application/json", bytes.NewReader(body), int64(len(body)))
+	assert.Equal(t, 201, req.Post().StatusCode)
+}
+
+func TestSignerError(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unsigned request was sent")
+	})
+
+	setup.Client.SetSigner(&hmacSigner{})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, true, res.IsError())
+	assert.Equal(t, "no key", res.Error())
+}
+
+type hmacSigner struct {
+	key string
+}
+
+func (s *hmacSigner) Sign(req *http.Request) error {
+	if len(s.key) == 0 {
+		return errors.New("no key")
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		if body, err = ioutil.ReadAll(rc); err != nil {
+			return err
+		}
+	}
+
+	req.Header.Set("X-Signature", hmacSignature(s.key, req.Method, req.URL.RequestURI(), body))
+	return nil
+}
+
+func hmacSignature(key, method, uri string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(method + "\n" + uri + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,79 @@
+package sawyer
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// GetStream sends a GET request, and returns the Response with its body open
+// and untouched, even for error statuses.  The caller must close the body, or
+// call Consume.  An error is returned if the server was not reached.
+func (r *Request) GetStream() (*Response, error) {
+	r.stream = true
+	res := r.Get()
+	r.stream = false
+
+	if res.Response == nil {
+		return nil, res.ResponseError
+	}
+	return res, nil
+}
+
+// Consume reads the rest of the body and closes it, so that the connection
+// can be reused.
+func (r *Response) Consume() error {
+	if r.BodyClosed || r.Response == nil {
+		return nil
+	}
+
+	_, err := io.Copy(ioutil.Discard, r.Body)
+	if cerr := r.Body.Close(); err == nil {
+		err = cerr
+	}
+	r.BodyClosed = true
+	return err
+}
+
+// DecodeStream decodes a stream of records, like newline-delimited JSON, as
+// they arrive.  The callback is called once per record, and decodes it with
+// the given function.  The body is closed when the stream ends, or when the
+// callback returns an error.
+//
+//	err := res.DecodeStream(func(decode func(interface{}) error) error {
+//		event := &Event{}
+//		if err := decode(event); err != nil {
+//			return err
+//		}
+//		return handle(event)
+//	})
+func (r *Response) DecodeStream(each func(decode func(v interface{}) error) error) error {
+	if r.ResponseError != nil || r.BodyClosed {
+		return r.ResponseError
+	}
+
+	if r.decodeType() == nil && r.fallbackType() == nil {
+		return errors.New("No media type for this response")
+	}
+
+	defer r.Body.Close()
+	r.BodyClosed = true
+
+	dec, err := r.decoder(r.Body)
+	if err != nil {
+		r.ResponseError = err
+		return err
+	}
+
+	// decoders without More end the stream by returning io.EOF
+	more, hasMore := dec.(interface{ More() bool })
+	for !hasMore || more.More() {
+		if err := each(dec.Decode); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
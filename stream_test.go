@@ -0,0 +1,162 @@
+package sawyer
+
+import (
+	"errors"
+	"github.com/bmizerany/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDecodeStream(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}` + "\n"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(`{"id": 2, "login": "finn"}` + "\n"))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, "ndjson", res.MediaType.Format)
+
+	var users []TestUser
+	err = res.DecodeStream(func(decode func(interface{}) error) error {
+		user := TestUser{}
+		if err := decode(&user); err != nil {
+			return err
+		}
+		users = append(users, user)
+		return nil
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []TestUser{{1, "sawyer"}, {2, "finn"}}, users)
+	assert.Equal(t, true, res.BodyClosed)
+}
+
+func TestDecodeStreamStops(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": 1, "login": "sawyer"}` + "\n" + `{"id": 2, "login": "finn"}` + "\n"))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	stop := errors.New("stop")
+	records := 0
+	err = req.Get().DecodeStream(func(decode func(interface{}) error) error {
+		records += 1
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, records)
+}
+
+func TestDecodeStreamWithoutDecoder(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/booya+booya")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}\n"))
+	})
+
+	req, err := setup.Client.NewRequest("users")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	err = res.DecodeStream(func(decode func(interface{}) error) error {
+		t.Error("callback should not be called")
+		return nil
+	})
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, err, res.ResponseError)
+}
+
+func TestGetStream(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(strings.Repeat("sawyer", 1000)))
+	})
+
+	req, err := setup.Client.NewRequest("download")
+	assert.Equal(t, nil, err)
+
+	res, err := req.GetStream()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 500, res.StatusCode)
+	assert.Equal(t, false, res.BodyClosed)
+	assert.Equal(t, false, res.IsError())
+
+	buf := make([]byte, 6)
+	_, err = io.ReadFull(res.Body, buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sawyer", string(buf))
+
+	assert.Equal(t, nil, res.Consume())
+	assert.Equal(t, true, res.BodyClosed)
+	assert.Equal(t, nil, res.Consume())
+}
+
+func TestGetStreamSkipsCache(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	requests := 0
+	setup.Mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(strings.Repeat("sawyer", 1000)))
+	})
+
+	cacher := newTestCacher()
+	setup.Client.Cacher = cacher
+
+	for i := 0; i < 2; i++ {
+		req, err := setup.Client.NewRequest("download")
+		assert.Equal(t, nil, err)
+
+		res, err := req.GetStream()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, false, res.FromCache)
+
+		buf := make([]byte, 6)
+		_, err = io.ReadFull(res.Body, buf)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "sawyer", string(buf))
+		assert.Equal(t, nil, res.Consume())
+	}
+
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 0, len(cacher.responses))
+}
+
+func TestGetStreamFailure(t *testing.T) {
+	setup := Setup(t)
+	setup.Teardown()
+
+	req, err := setup.Client.NewRequest("download")
+	assert.Equal(t, nil, err)
+
+	res, err := req.GetStream()
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, (*Response)(nil), res)
+}
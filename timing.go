@@ -0,0 +1,80 @@
+package sawyer
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing holds how long each phase of a request took, if the Client's
+// TraceTiming option is set.  Phases that did not happen, like DNS for a
+// reused connection, are zero.  Total is measured until the response headers
+// are read.
+type Timing struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+	Total     time.Duration
+}
+
+// timingTrace collects a Timing from httptrace callbacks.
+type timingTrace struct {
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       Timing
+	mutex        sync.Mutex
+}
+
+func newTimingTrace() *timingTrace {
+	return &timingTrace{start: time.Now()}
+}
+
+func (t *timingTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.since(&t.timing.DNS, &t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mark(&t.connectStart)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.since(&t.timing.Connect, &t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.since(&t.timing.TLS, &t.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			t.since(&t.timing.FirstByte, &t.start)
+		},
+	}
+}
+
+func (t *timingTrace) mark(at *time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	*at = time.Now()
+}
+
+func (t *timingTrace) since(d *time.Duration, at *time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	*d = time.Since(*at)
+}
+
+// done returns the Timing, ending the Total duration.
+func (t *timingTrace) done() Timing {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.timing.Total = time.Since(t.start)
+	return t.timing
+}
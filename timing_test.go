@@ -0,0 +1,41 @@
+package sawyer
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTraceTiming(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.TraceTiming = true
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+
+	res := req.Get()
+	assert.Equal(t, 204, res.StatusCode)
+	assert.Tf(t, res.Timing.Total > 0, "Bad total: %s", res.Timing.Total)
+	assert.Tf(t, res.Timing.FirstByte >= 5*time.Millisecond, "Bad first byte: %s", res.Timing.FirstByte)
+	assert.Tf(t, res.Timing.FirstByte <= res.Timing.Total, "First byte %s after total %s", res.Timing.FirstByte, res.Timing.Total)
+}
+
+func TestNoTraceTiming(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Timing{}, req.Get().Timing)
+}
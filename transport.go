@@ -0,0 +1,133 @@
+package sawyer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SetTransport sets the transport used by requests built by this Client,
+// instead of the Transport of its HttpClient.  The Client's middleware still
+// wraps it.  The transport is copied, so the other transport setters never
+// change the given one, and any of them called before are applied to the copy
+// again.
+func (c *Client) SetTransport(t *http.Transport) {
+	c.transport = nil
+	if t != nil || len(c.transportOpts) > 0 {
+		c.newTransport(t)
+	}
+}
+
+// SetMaxIdleConnsPerHost sets how many idle connections are kept for reuse
+// per host.
+func (c *Client) SetMaxIdleConnsPerHost(n int) {
+	c.configureTransport(func(t *http.Transport) {
+		t.MaxIdleConnsPerHost = n
+	})
+}
+
+// SetIdleConnTimeout sets how long idle connections are kept for reuse.
+func (c *Client) SetIdleConnTimeout(d time.Duration) {
+	c.configureTransport(func(t *http.Transport) {
+		t.IdleConnTimeout = d
+	})
+}
+
+// SetTLSConfig sets the TLS configuration of the Client's transport.
+func (c *Client) SetTLSConfig(cfg *tls.Config) {
+	c.configureTransport(func(t *http.Transport) {
+		t.TLSClientConfig = cfg
+	})
+}
+
+// SetClientCertificate sets the certificate presented to servers that ask for
+// one, such as for mutual TLS.
+func (c *Client) SetClientCertificate(cert tls.Certificate) {
+	c.configureTransport(func(t *http.Transport) {
+		tlsConfig(t).Certificates = []tls.Certificate{cert}
+	})
+}
+
+// SetRootCAs sets the certificate authorities used to verify servers, instead
+// of the system's.
+func (c *Client) SetRootCAs(pool *x509.CertPool) {
+	c.configureTransport(func(t *http.Transport) {
+		tlsConfig(t).RootCAs = pool
+	})
+}
+
+// SetProxy sends requests built by this Client through the given proxy.  A
+// nil URL sends them directly, whatever the environment says.
+func (c *Client) SetProxy(proxyURL *url.URL) {
+	c.configureTransport(func(t *http.Transport) {
+		if proxyURL == nil {
+			t.Proxy = nil
+		} else {
+			t.Proxy = http.ProxyURL(proxyURL)
+		}
+	})
+}
+
+// SetProxyFromEnvironment picks the proxy from the HTTP_PROXY, HTTPS_PROXY,
+// and NO_PROXY environment variables, like http.DefaultTransport does.
+func (c *Client) SetProxyFromEnvironment() {
+	c.configureTransport(func(t *http.Transport) {
+		t.Proxy = http.ProxyFromEnvironment
+	})
+}
+
+// tlsConfig returns a copy of the transport's TLS configuration that can be
+// changed, so that one given to SetTLSConfig is left untouched.
+func tlsConfig(t *http.Transport) *tls.Config {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = new(tls.Config)
+	} else {
+		t.TLSClientConfig = t.TLSClientConfig.Clone()
+	}
+	return t.TLSClientConfig
+}
+
+// configureTransport changes the Client's own transport, and remembers the
+// change so that it is applied again to a transport given to SetTransport
+// later.
+func (c *Client) configureTransport(set func(*http.Transport)) {
+	transport := c.ownTransport()
+	c.transportOpts = append(c.transportOpts, set)
+	set(transport)
+}
+
+// ownTransport returns the transport set with SetTransport, or a new one.
+func (c *Client) ownTransport() *http.Transport {
+	if c.transport == nil {
+		c.newTransport(nil)
+	}
+	return c.transport
+}
+
+// newTransport sets the Client's transport to a copy of the given one, with
+// the settings made so far.  Without one, it starts as a copy of the
+// HttpClient's Transport, if it is an *http.Transport, or else of
+// http.DefaultTransport.  The HttpClient itself is never changed, since it may
+// be shared.
+func (c *Client) newTransport(base *http.Transport) {
+	if base == nil {
+		base, _ = http.DefaultTransport.(*http.Transport)
+		if c.HttpClient != nil {
+			if t, ok := c.HttpClient.Transport.(*http.Transport); ok {
+				base = t
+			}
+		}
+	}
+
+	if base != nil {
+		c.transport = base.Clone()
+	} else {
+		c.transport = new(http.Transport)
+	}
+
+	for _, set := range c.transportOpts {
+		set(c.transport)
+	}
+}
@@ -0,0 +1,202 @@
+package sawyer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTransportSettings(t *testing.T) {
+	base := &http.Transport{MaxIdleConns: 7}
+	client, err := NewFromString("https://api.github.com", &http.Client{Transport: base})
+	assert.Equal(t, nil, err)
+
+	client.SetMaxIdleConnsPerHost(20)
+	client.SetIdleConnTimeout(time.Minute)
+
+	transport := client.httpClient().Transport.(*http.Transport)
+	assert.Equal(t, 7, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.Equal(t, 0, base.MaxIdleConnsPerHost)
+	assert.Equal(t, http.RoundTripper(base), client.HttpClient.Transport)
+
+	own := &http.Transport{MaxIdleConns: 3}
+	client.SetTransport(own)
+	transport = client.httpClient().Transport.(*http.Transport)
+	assert.Equal(t, 3, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+
+	client.SetMaxIdleConnsPerHost(5)
+	transport = client.httpClient().Transport.(*http.Transport)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 0, own.MaxIdleConnsPerHost)
+	assert.NotEqual(t, own, transport)
+
+	clone := client.Clone()
+	clone.SetMaxIdleConnsPerHost(1)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+}
+
+func TestSetTransportKeepsSettings(t *testing.T) {
+	client, err := NewFromString("https://api.github.com", nil)
+	assert.Equal(t, nil, err)
+
+	pool := x509.NewCertPool()
+	proxyURL, err := url.Parse("http://proxy.example.com")
+	assert.Equal(t, nil, err)
+	cfg := &tls.Config{ServerName: "api.github.com"}
+
+	client.SetTLSConfig(cfg)
+	client.SetRootCAs(pool)
+	client.SetClientCertificate(tls.Certificate{})
+	client.SetProxy(proxyURL)
+
+	own := &http.Transport{MaxIdleConns: 3}
+	client.SetTransport(own)
+
+	transport := client.httpClient().Transport.(*http.Transport)
+	assert.Equal(t, 3, transport.MaxIdleConns)
+	assert.Equal(t, "api.github.com", transport.TLSClientConfig.ServerName)
+	assert.Equal(t, pool, transport.TLSClientConfig.RootCAs)
+	assert.Equal(t, 1, len(transport.TLSClientConfig.Certificates))
+	assert.Equal(t, true, transport.Proxy != nil)
+	assert.Equal(t, (*x509.CertPool)(nil), cfg.RootCAs)
+	assert.Equal(t, 0, len(cfg.Certificates))
+	assert.Equal(t, true, own.Proxy == nil)
+}
+
+func TestTransportSettersLeaveGivenTransport(t *testing.T) {
+	client, err := NewFromString("https://api.github.com", nil)
+	assert.Equal(t, nil, err)
+
+	cfg := &tls.Config{ServerName: "api.github.com"}
+	own := &http.Transport{TLSClientConfig: cfg}
+	client.SetTransport(own)
+
+	pool := x509.NewCertPool()
+	client.SetRootCAs(pool)
+	client.SetProxyFromEnvironment()
+	client.SetIdleConnTimeout(time.Minute)
+
+	transport := client.httpClient().Transport.(*http.Transport)
+	assert.Equal(t, "api.github.com", transport.TLSClientConfig.ServerName)
+	assert.Equal(t, pool, transport.TLSClientConfig.RootCAs)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.Equal(t, (*x509.CertPool)(nil), cfg.RootCAs)
+	assert.Equal(t, true, own.Proxy == nil)
+	assert.Equal(t, time.Duration(0), own.IdleConnTimeout)
+
+	defaults := http.DefaultTransport.(*http.Transport)
+	client.SetTransport(defaults)
+	client.SetMaxIdleConnsPerHost(42)
+	assert.NotEqual(t, 42, defaults.MaxIdleConnsPerHost)
+	assert.Equal(t, 42, client.httpClient().Transport.(*http.Transport).MaxIdleConnsPerHost)
+}
+
+func TestTransportRequests(t *testing.T) {
+	setup := Setup(t)
+	defer setup.Teardown()
+
+	setup.Mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	setup.Client.SetMaxIdleConnsPerHost(2)
+
+	req, err := setup.Client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+}
+
+func TestMutualTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 1, len(r.TLS.PeerCertificates))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	client, err := NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+
+	req, err := client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, req.Get().IsError())
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	cfg := &tls.Config{RootCAs: pool}
+	client.SetTLSConfig(cfg)
+
+	req, err = client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, req.Get().IsError())
+
+	// the server's own certificate is good enough for RequireAnyClientCert
+	client.SetClientCertificate(srv.TLS.Certificates[0])
+	assert.Equal(t, 0, len(cfg.Certificates))
+
+	req, err = client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+}
+
+func TestSetRootCAs(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client, err := NewFromString(srv.URL, nil)
+	assert.Equal(t, nil, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	client.SetTransport(&http.Transport{MaxIdleConnsPerHost: 3})
+	client.SetRootCAs(pool)
+
+	transport := client.httpClient().Transport.(*http.Transport)
+	assert.Equal(t, 3, transport.MaxIdleConnsPerHost)
+
+	req, err := client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+}
+
+func TestSetProxy(t *testing.T) {
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	assert.Equal(t, nil, err)
+
+	client, err := NewFromString("http://api.example.invalid", nil)
+	assert.Equal(t, nil, err)
+	client.SetProxy(proxyURL)
+
+	req, err := client.NewRequest("user")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 204, req.Get().StatusCode)
+	assert.Equal(t, "http://api.example.invalid/user", <-proxied)
+
+	client.SetProxy(nil)
+	assert.Equal(t, true, client.httpClient().Transport.(*http.Transport).Proxy == nil)
+
+	client.SetProxyFromEnvironment()
+	assert.Equal(t, true, client.httpClient().Transport.(*http.Transport).Proxy != nil)
+}